@@ -1,15 +1,20 @@
 package databases
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/endlessChallengeSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
+	"golang.org/x/exp/slog"
 )
 
-func ListChallenges(db *sql.DB, limit int) ([]models.Challenge, error) {
+func ListChallenges(ctx context.Context, db *sql.DB, limit int) ([]models.Challenge, error) {
+	ctx, span := tracing.Start(ctx, "databases.ListChallenges")
+	defer span.End()
+
 	var query string = `
 		SELECT 
 		ID, PlayerID, Amount, Status, Won, CreatedAt, Probability 
@@ -23,7 +28,7 @@ func ListChallenges(db *sql.DB, limit int) ([]models.Challenge, error) {
 		args = append(args, limit)
 	}
 
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 
 	if err != nil {
 		return nil, fmt.Errorf("error querying database with ListChallenges: %w", err)
@@ -51,10 +56,13 @@ func ListChallenges(db *sql.DB, limit int) ([]models.Challenge, error) {
 	return challenges, nil
 }
 
-func GetLastChallenge(db *sql.DB, playerID int) (*time.Time, float64, error) {
+func GetLastChallenge(ctx context.Context, db *sql.DB, playerID int) (*time.Time, float64, error) {
+	ctx, span := tracing.Start(ctx, "databases.GetLastChallenge")
+	defer span.End()
+
 	var lastChallengeTime time.Time
 	var lastProbability float64
-	err := db.QueryRow(`
+	err := db.QueryRowContext(ctx, `
 		SELECT 
 		CreatedAt, Probability
 		FROM Challenge 
@@ -71,8 +79,11 @@ func GetLastChallenge(db *sql.DB, playerID int) (*time.Time, float64, error) {
 	return &lastChallengeTime, lastProbability, nil
 }
 
-func AddNewChallenge(tx *sql.Tx, newChallengeNeed models.NewChallengeNeed, status models.Status, probability float64) (int, error) {
-	result, err := tx.Exec(`
+func AddNewChallenge(ctx context.Context, tx *sql.Tx, newChallengeNeed models.NewChallengeNeed, status models.Status, probability float64) (int, error) {
+	ctx, span := tracing.Start(ctx, "databases.AddNewChallenge")
+	defer span.End()
+
+	result, err := tx.ExecContext(ctx, `
 		INSERT INTO Challenge (PlayerID, Amount, Status, Won, CreatedAt, Probability) 
 		VALUES (?, ?, ?, false, NOW(), ?)
 	`, newChallengeNeed.PlayerID,
@@ -86,8 +97,30 @@ func AddNewChallenge(tx *sql.Tx, newChallengeNeed models.NewChallengeNeed, statu
 	return int(challengeID), nil
 }
 
-func UpdatePricePool(tx *sql.Tx, amount float64) error {
-	_, err := tx.Exec(`
+func GetPrizePool(ctx context.Context, db *sql.DB) (float64, error) {
+	ctx, span := tracing.Start(ctx, "databases.GetPrizePool")
+	defer span.End()
+
+	var amount float64
+	err := db.QueryRowContext(ctx, `
+		SELECT 
+		Amount 
+		FROM PrizePool 
+		WHERE ID = 1
+	`).Scan(
+		&amount,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching prize pool amount: %w", err)
+	}
+	return amount, nil
+}
+
+func UpdatePricePool(ctx context.Context, tx *sql.Tx, amount float64) error {
+	ctx, span := tracing.Start(ctx, "databases.UpdatePricePool")
+	defer span.End()
+
+	_, err := tx.ExecContext(ctx, `
 		UPDATE PrizePool 
 		SET Amount = Amount + ? 
 		WHERE ID = 1
@@ -98,8 +131,11 @@ func UpdatePricePool(tx *sql.Tx, amount float64) error {
 	return nil
 }
 
-func UpdateChallenge(tx *sql.Tx, status models.Status, won bool, playerID int) error {
-	_, err := tx.Exec(`
+func UpdateChallenge(ctx context.Context, tx *sql.Tx, status models.Status, won bool, playerID int) error {
+	ctx, span := tracing.Start(ctx, "databases.UpdateChallenge")
+	defer span.End()
+
+	_, err := tx.ExecContext(ctx, `
 		UPDATE Challenge 
 		SET Status = ?, Won = ? WHERE PlayerID = ?
 	`, int(status),
@@ -111,10 +147,13 @@ func UpdateChallenge(tx *sql.Tx, status models.Status, won bool, playerID int) e
 	return nil
 }
 
-func DistributePrizePool(tx *sql.Tx, challengeID int, playerID int, status models.Status) error {
+func DistributePrizePool(ctx context.Context, tx *sql.Tx, challengeID int, playerID int, status models.Status) error {
+	ctx, span := tracing.Start(ctx, "databases.DistributePrizePool")
+	defer span.End()
+
 	// know the player last win how much money
 	var prize float64
-	err := tx.QueryRow(`
+	err := tx.QueryRowContext(ctx, `
 		SELECT 
 		Amount 
 		FROM PrizePool 
@@ -127,7 +166,7 @@ func DistributePrizePool(tx *sql.Tx, challengeID int, playerID int, status model
 	}
 
 	// Update last challenges's won
-	_, err = tx.Exec(`
+	_, err = tx.ExecContext(ctx, `
 		UPDATE Challenge 
 		SET Won = 1, Probability = 0, Status = ? 
 		WHERE ID = ? AND PlayerID = ?
@@ -139,7 +178,7 @@ func DistributePrizePool(tx *sql.Tx, challengeID int, playerID int, status model
 	}
 
 	// Reset prize pool
-	_, err = tx.Exec(
+	_, err = tx.ExecContext(ctx,
 		`UPDATE PrizePool 
 		SET Amount = 0 
 		WHERE ID = 1
@@ -148,14 +187,16 @@ func DistributePrizePool(tx *sql.Tx, challengeID int, playerID int, status model
 		return fmt.Errorf("error resetting prize pool: %w", err)
 	}
 
-	log.Printf("Prize pool of %f distributed to player %d and challenge %d", prize, playerID, challengeID)
+	slog.InfoContext(ctx, "prize pool distributed", "prize", prize, "player_id", playerID, "challenge_id", challengeID)
 	return nil
 }
 
-func UpdateProbability(tx *sql.Tx, challengeID int, playerID int, probability float64, status models.Status) error {
+func UpdateProbability(ctx context.Context, tx *sql.Tx, challengeID int, playerID int, probability float64, status models.Status) error {
+	ctx, span := tracing.Start(ctx, "databases.UpdateProbability")
+	defer span.End()
 
 	// Update last challenge's won
-	_, err := tx.Exec(`
+	_, err := tx.ExecContext(ctx, `
 		UPDATE Challenge 
 		SET 
 		Probability = ?, 
@@ -170,6 +211,6 @@ func UpdateProbability(tx *sql.Tx, challengeID int, playerID int, probability fl
 		return fmt.Errorf("error updating player's balance: %w", err)
 	}
 
-	log.Printf("probability of %f with player %d and challenge %d", probability, playerID, challengeID)
+	slog.DebugContext(ctx, "probability updated", "probability", probability, "player_id", playerID, "challenge_id", challengeID)
 	return nil
 }
@@ -9,6 +9,7 @@ const docTemplate = `{
     "info": {
         "description": "{{escape .Description}}",
         "title": "{{.Title}}",
+        "termsOfService": "http://swagger.io/terms/",
         "contact": {
             "name": "Steven Poon",
             "url": "https://github.com/RYANCOAL9999",
@@ -72,6 +73,14 @@ const docTemplate = `{
         },
         "/challenges/join": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "description": "Allows a player to join a new challenge, provided they haven't participated in the last minute. It processes the challenge creation within a transaction, updates the prize pool, and starts a background task to calculate the challenge result after 30 seconds. Returns the status of the challenge creation.",
                 "consumes": [
                     "application/json"
@@ -107,8 +116,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "425": {
-                        "description": "Too many requests if attempting to join within a minute",
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "player_id of another player without permission challenges:join_any",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded, each player can join once per minute",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -121,9 +142,127 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Reports that the process is alive, it does not check any dependency.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness",
+                "responses": {
+                    "200": {
+                        "description": "The process is alive",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Runs the readiness checks of the service, such as the database, the schema version and the services it depends on, with the status and latency of each.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness",
+                "responses": {
+                    "200": {
+                        "description": "Every check passed",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "A check failed or the service is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "Git commit and build time of the binary and the Go version it was built with.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Build information",
+                "responses": {
+                    "200": {
+                        "description": "Build information",
+                        "schema": {
+                            "$ref": "#/definitions/health.Version"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
+        "health.CheckResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "number",
+                    "example": 1.25
+                },
+                "name": {
+                    "type": "string",
+                    "example": "database"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/health.CheckResult"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "health.Version": {
+            "type": "object",
+            "properties": {
+                "build_time": {
+                    "type": "string",
+                    "example": "2024-07-27T16:09:21Z"
+                },
+                "commit": {
+                    "type": "string",
+                    "example": "9fd67d9"
+                },
+                "go_version": {
+                    "type": "string",
+                    "example": "go1.20.14"
+                }
+            }
+        },
         "models.Challenge": {
             "type": "object",
             "required": [
@@ -163,6 +302,9 @@ const docTemplate = `{
             "properties": {
                 "error": {
                     "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
                 }
             }
         },
@@ -177,8 +319,7 @@ const docTemplate = `{
         "models.NewChallengeNeed": {
             "type": "object",
             "required": [
-                "amount",
-                "player_id"
+                "amount"
             ],
             "properties": {
                 "amount": {
@@ -200,6 +341,20 @@ const docTemplate = `{
                 "Joined"
             ]
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key of another service from POST /api_keys of playerManagementSystem",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "Access token from POST /auth/login of playerManagementSystem, as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -1,8 +1,8 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
-	"log"
 	"math/rand"
 	"net/http"
 	"strconv"
@@ -10,16 +10,30 @@ import (
 
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/endlessChallengeSystem/databases"
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/endlessChallengeSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/lifecycle"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
 )
 
 // 1% chance of winning
 const winProbability float64 = 0.01
 
-func CalculateChallengeResult(db *sql.DB, challengeID int, playerID int, probability float64) {
+// CalculateChallengeResult decides the challenge after a delay of 30 seconds, or right away
+// when ctx is done so a shutdown does not leave the challenge in Ready. The result is
+// traced and logged with the trace and request ID of ctx, the join the challenge was created by.
+func CalculateChallengeResult(ctx context.Context, db *sql.DB, challengeID int, playerID int, probability float64) {
 
 	// Delay the calculation by 30 seconds
-	time.Sleep(30 * time.Second)
+	select {
+	case <-time.After(30 * time.Second):
+	case <-ctx.Done():
+	}
+
+	// The result is stored even when the shutdown has cancelled ctx
+	ctx, span := tracing.Start(tracing.Follow(context.Background(), ctx), "CalculateChallengeResult")
+	defer span.End()
 
 	localProbability := winProbability + probability
 
@@ -27,40 +41,42 @@ func CalculateChallengeResult(db *sql.DB, challengeID int, playerID int, probabi
 
 	const joined models.Status = models.Joined
 
-	tx, err := db.Begin()
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Printf("Failed to start transaction: %v", err)
+		slog.ErrorContext(ctx, "failed to start the transaction of the challenge result", "challenge_id", challengeID, "player_id", playerID, "error", err)
 		return
 	}
 	// defer tx.Rollback()
 	defer func() {
 		if p := recover(); p != nil {
 			tx.Rollback()
-			log.Printf("Recovered rollback with CalculateChallengeResult: %v", p)
+			slog.ErrorContext(ctx, "recovered the challenge result calculation, rolled back", "challenge_id", challengeID, "player_id", playerID, "panic", p)
 			// panic(p) // Re-throw panic after rollback
 		} else if err != nil {
 			tx.Rollback() // Rollback on error
-			log.Printf("Recovered error with CalculateChallengeResult: %v", p)
+			slog.ErrorContext(ctx, "rolled back the challenge result", "challenge_id", challengeID, "player_id", playerID, "error", err)
 		} else {
 			err = tx.Commit() // Commit on success
 			if err != nil {
-				log.Printf("Failed to commit transaction wth CalculateChallengeResult: %v", err)
+				slog.ErrorContext(ctx, "failed to commit the challenge result", "challenge_id", challengeID, "player_id", playerID, "error", err)
+			} else if won {
+				challengesWon.Inc()
 			}
 		}
 	}()
 
 	if won {
-		err = databases.DistributePrizePool(tx, challengeID, playerID, joined)
+		err = databases.DistributePrizePool(ctx, tx, challengeID, playerID, joined)
 	} else {
-		err = databases.UpdateProbability(tx, challengeID, playerID, localProbability, joined)
+		err = databases.UpdateProbability(ctx, tx, challengeID, playerID, localProbability, joined)
 	}
 
 	if err != nil {
-		log.Printf("Failed to distribute prize pool: %v", err)
+		slog.ErrorContext(ctx, "failed to store the challenge result", "challenge_id", challengeID, "player_id", playerID, "won", won, "error", err)
 		return
 	}
 
-	log.Printf("Challenge result calculated for player %d. Won: %v", playerID, won)
+	slog.InfoContext(ctx, "challenge result calculated", "challenge_id", challengeID, "player_id", playerID, "won", won)
 }
 
 // @Summary      Join a challenge
@@ -71,27 +87,43 @@ func CalculateChallengeResult(db *sql.DB, challengeID int, playerID int, probabi
 // @Param        challenge  body  models.NewChallengeNeed  true  "Details for joining the challenge"
 // @Success      201  {object}  models.JoinChallengeResponse "Challenge joined successfully, returns the status of the challenge, it represent as number, 1 is joined, 0 is Ready"
 // @Failure      400  {object}  models.ErrorResponse "Bad request due to invalid input data"
-// @Failure      425  {object}  models.ErrorResponse "Too many requests if attempting to join within a minute"
+// @Failure      401  {object}  models.ErrorResponse "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse "player_id of another player without permission challenges:join_any"
+// @Failure      429  {object}  models.ErrorResponse "Rate limit exceeded, each player can join once per minute"
 // @Failure      500  {object}  models.ErrorResponse "Internal server error during challenge creation or transaction"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /challenges/join [post]
-func JoinChallenges(c *gin.Context, db *sql.DB) {
+func JoinChallenges(c *gin.Context, db *sql.DB, limiter middleware.RateLimitStore, workers *lifecycle.Workers) {
 	var newChallengeNeed models.NewChallengeNeed
 
 	if err := c.ShouldBindJSON(&newChallengeNeed); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
 		return
 	}
 
-	var probability float64 = 0
+	// The authenticated player joins, player_id can be left out, API keys must name the player
+	if playerID, ok := middleware.PlayerID(c); ok && newChallengeNeed.PlayerID == 0 {
+		newChallengeNeed.PlayerID = playerID
+	}
+	if newChallengeNeed.PlayerID == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "player_id is required"))
+		return
+	} else if !middleware.IsPlayer(c, newChallengeNeed.PlayerID) && !Policy.Allows(c, PermChallengesJoinAny) {
+		c.JSON(http.StatusForbidden, errorResponse(c, "missing permission "+PermChallengesJoinAny+" to join challenges for other players"))
+		return
+	}
 
-	lastChallengeTime, lastprobability, err := databases.GetLastChallenge(db, newChallengeNeed.PlayerID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+	// Each player joins once per minute, taking the token is atomic unlike comparing with the last challenge
+	if !middleware.Limit(c, limiter, JoinRateLimit, "player:"+strconv.Itoa(newChallengeNeed.PlayerID)) {
 		return
 	}
 
-	if time.Since(*lastChallengeTime) < time.Minute {
-		c.JSON(http.StatusTooEarly, models.ErrorResponse{Error: "You can only participate once per minute"})
+	var probability float64 = 0
+
+	lastChallengeTime, lastprobability, err := databases.GetLastChallenge(c.Request.Context(), db, newChallengeNeed.PlayerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
 
@@ -104,48 +136,50 @@ func JoinChallenges(c *gin.Context, db *sql.DB) {
 	}
 
 	// Start a transaction
-	tx, err := db.Begin()
+	tx, err := db.BeginTx(c.Request.Context(), nil)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to start transaction"})
+		c.JSON(http.StatusInternalServerError, errorResponse(c, "Failed to start transaction"))
 		return
 	}
 	// defer tx.Rollback()
 	defer func() {
 		if p := recover(); p != nil {
 			tx.Rollback()
-			log.Printf("Recovered rollback with JoinChallenges: %v", p)
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Internal server error"})
+			slog.ErrorContext(c.Request.Context(), "recovered joining the challenge, rolled back", "panic", p)
+			c.JSON(http.StatusInternalServerError, errorResponse(c, "Internal server error"))
 			// panic(p) // Re-throw panic after rollback
 		} else if err != nil {
 			tx.Rollback() // Rollback on error
-			log.Printf("Recovered error with JoinChallenges: %v", p)
-			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to join challenge"})
+			slog.WarnContext(c.Request.Context(), "rolled back joining the challenge", "error", err)
+			c.JSON(http.StatusInternalServerError, errorResponse(c, "Failed to join challenge"))
 		} else {
 			err = tx.Commit() // Commit on success
 			if err != nil {
-				log.Printf("Failed to commit transaction with JoinChallenges: %v", err)
-				c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to commit transaction"})
+				slog.ErrorContext(c.Request.Context(), "failed to commit joining the challenge", "error", err)
+				c.JSON(http.StatusInternalServerError, errorResponse(c, "Failed to commit transaction"))
+			} else {
+				challengesJoined.Inc()
 			}
 		}
 	}()
 
-	lastChallengeID, err := databases.AddNewChallenge(tx, newChallengeNeed, status, probability)
+	lastChallengeID, err := databases.AddNewChallenge(c.Request.Context(), tx, newChallengeNeed, status, probability)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to Add New Challenge "})
+		c.JSON(http.StatusInternalServerError, errorResponse(c, "Failed to Add New Challenge "))
 		return
 	}
 
 	// need to update PrizePool Value
-	err = databases.UpdatePricePool(tx, newChallengeNeed.Amount)
+	err = databases.UpdatePricePool(c.Request.Context(), tx, newChallengeNeed.Amount)
 	if err != nil {
-		c.JSON(http.StatusTooEarly, models.ErrorResponse{Error: "Failed to update price pool"})
+		c.JSON(http.StatusTooEarly, errorResponse(c, "Failed to update price pool"))
 		return
 	}
 
-	go func() {
-		time.Sleep(30 * time.Second)
-		CalculateChallengeResult(db, lastChallengeID, newChallengeNeed.PlayerID, probability)
-	}()
+	request := c.Request.Context()
+	workers.Go(func(ctx context.Context) {
+		CalculateChallengeResult(tracing.Follow(ctx, request), db, lastChallengeID, newChallengeNeed.PlayerID, probability)
+	})
 
 	c.JSON(http.StatusCreated, models.JoinChallengeResponse{Status: status})
 }
@@ -163,9 +197,9 @@ func JoinChallenges(c *gin.Context, db *sql.DB) {
 func ShowChallenges(c *gin.Context, db *sql.DB) {
 	var args interface{}
 	limit, _ := strconv.Atoi(c.Query("limit"))
-	challenges, err := databases.ListChallenges(db, limit)
+	challenges, err := databases.ListChallenges(c.Request.Context(), db, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
 	if len(challenges) > 1 {
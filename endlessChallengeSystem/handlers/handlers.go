@@ -3,10 +3,19 @@ package handlers
 import (
 	"database/sql"
 
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/endlessChallengeSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/lifecycle"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupChallengeRoutes(challenges *gin.RouterGroup, db *sql.DB) {
-	challenges.POST("/", func(c *gin.Context) { JoinChallenges(c, db) })
+// errorResponse is the body of a failed request, carrying its ID to find its logs.
+func errorResponse(c *gin.Context, message string) models.ErrorResponse {
+	return models.ErrorResponse{Error: message, RequestID: middleware.RequestID(c)}
+}
+
+func SetupChallengeRoutes(challenges *gin.RouterGroup, db *sql.DB, limiter middleware.RateLimitStore, workers *lifecycle.Workers) {
+	challenges.Use(middleware.RateLimit(limiter, DefaultRateLimit))
+	challenges.POST("/", middleware.RequireAuth(), func(c *gin.Context) { JoinChallenges(c, db, limiter, workers) })
 	challenges.GET("/results", func(c *gin.Context) { ShowChallenges(c, db) })
 }
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"math"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/endlessChallengeSystem/databases"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/exp/slog"
+)
+
+// Domain metrics of the challenges routes.
+var (
+	challengesJoined = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "challenges_joined_total",
+		Help:      "Challenges joined.",
+	})
+
+	challengesWon = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "challenges_won_total",
+		Help:      "Challenges won, each win takes the whole prize pool.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(challengesJoined, challengesWon)
+}
+
+// RegisterPrizePoolMetric exports the amount of the prize pool, read from db on every scrape
+// so it is the same whichever instance is scraped.
+func RegisterPrizePoolMetric(db *sql.DB) {
+	prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "prize_pool_amount",
+		Help:      "Amount of the prize pool.",
+	}, func() float64 {
+		amount, err := databases.GetPrizePool(context.Background(), db)
+		if err != nil {
+			slog.Error("failed to read the prize pool for the metrics", "error", err)
+			return math.NaN()
+		}
+		return amount
+	}))
+}
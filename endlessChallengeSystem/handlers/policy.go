@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
+)
+
+// Permissions of the challenges routes.
+const (
+	PermChallengesJoinAny = "challenges:join_any"
+)
+
+// Policy grants the permissions to roles, players can always join challenges for themselves.
+var Policy = middleware.Policy{
+	PermChallengesJoinAny: {middleware.RoleGameMaster, middleware.RoleAdmin},
+}
+
+// Rate limits of the routes, each player can join one challenge per minute
+// whoever makes the request.
+var (
+	DefaultRateLimit = middleware.RateLimitPolicy{Name: "challenges", Limit: 120, Window: time.Minute}
+	JoinRateLimit    = middleware.RateLimitPolicy{Name: "challenges:join", Limit: 1, Window: time.Minute}
+)
@@ -1,20 +1,32 @@
 package main
 
+// The health routes are documented in the shared health package
+//go:generate swag init -d ./,../shared/health
+
 import (
-	"database/sql"
+	"context"
+	"flag"
 	"fmt"
-	"log"
+	"net/http"
 	"os"
+	"time"
 
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/endlessChallengeSystem/config"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/endlessChallengeSystem/databases"
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/endlessChallengeSystem/docs"
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/endlessChallengeSystem/handlers"
-	"github.com/joho/godotenv"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/health"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/lifecycle"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/logging"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/metrics"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
 
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	"github.com/gin-gonic/gin"
-	_ "github.com/go-sql-driver/mysql"
+	"golang.org/x/exp/slog"
 )
 
 // @title Endless Challenge System API
@@ -31,50 +43,139 @@ import (
 
 // @host :8085
 // @BasePath /v2
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Access token from POST /auth/login of playerManagementSystem, as "Bearer <token>"
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key of another service from POST /api_keys of playerManagementSystem
 func main() {
-	// Load environment variables from .env file
-	err := godotenv.Load()
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML file with the settings, the environment and .env take precedence")
+	printConfig := flag.Bool("print-config", false, "print the effective settings with the secrets redacted and exit")
+	flag.Parse()
+
+	// Load the settings from the environment, .env and the config file
+	var cfg config.Config
+	if err := config.Load(&cfg, *configFile); err != nil {
+		logging.Fatal("error loading the config", err)
+	}
+	if *printConfig {
+		if err := config.Print(os.Stdout, &cfg); err != nil {
+			logging.Fatal("error printing the config", err)
+		}
+		return
+	}
+
+	// Structured logs of the requests and the background work, as set by LOG_LEVEL and LOG_FORMAT
+	if err := logging.Setup("endlessChallengeSystem", cfg.Log.Level, cfg.Log.Format); err != nil {
+		logging.Fatal("error setting up the logs", err)
+	}
+
+	// Traces of the requests and the queries, exported as set by TRACING_EXPORTER
+	shutdownTracing, err := tracing.Setup("endlessChallengeSystem", cfg.Tracing.Exporter, cfg.Tracing.Endpoint, cfg.Tracing.File)
 	if err != nil {
-		log.Fatalf("Error loading .env file")
+		logging.Fatal("error setting up tracing", err)
 	}
+
 	// Database connection
-	db, err := sql.Open("mysql", os.Getenv("DB_CONNECTION_STRING")+"?parseTime=true")
+	db, err := cfg.Database.Open()
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("error connecting to the database", err)
 	}
 	defer db.Close()
 
-	// Test the connection
-	err = db.Ping()
+	// Keys the access tokens of every request are verified with
+	keys, err := middleware.LoadKeySet([]byte(cfg.Auth.JWTSecret), cfg.Auth.JWKSFile)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("error loading the token keys", err)
 	}
 
-	//Using the Default setting
-	var r *gin.Engine = gin.Default()
+	// Token buckets of the rate limits, shared through MySQL with RATE_LIMIT_BACKEND=mysql
+	limiter, err := middleware.NewRateLimitStore(cfg.RateLimit.Backend, db)
+	if err != nil {
+		logging.Fatal("error creating the rate limit store", err)
+	}
 
-	//write the logs to gin.DefaultWriter
-	r.Use(gin.Logger())
+	// Lifecycle of the server and the background workers
+	app := lifecycle.New()
+
+	//Using a bare engine, the logs and the recovery are added below
+	var r *gin.Engine = gin.New()
+
+	//Trust X-Forwarded-For from the nginx proxy only
+	if err := r.SetTrustedProxies(cfg.RateLimit.TrustedProxies); err != nil {
+		logging.Fatal("error setting the trusted proxies", err)
+	}
+
+	//Take the X-Request-ID of the requests or generate one, echoed in the responses
+	r.Use(middleware.AssignRequestID())
+
+	//Trace the requests, continuing the trace of their traceparent header
+	r.Use(tracing.Middleware())
+
+	//Log the requests as JSON with their request ID and trace
+	r.Use(logging.Middleware())
+
+	//Count the requests and their latency, outside Recovery so panics are counted as 500
+	r.Use(metrics.Middleware())
 
 	//Recovery returns a middleware if server is panics
 	r.Use(gin.Recovery())
 
+	//Authenticate the bearer token of the requests that have one
+	r.Use(middleware.Authenticate(keys))
+
+	//Authenticate the API key of the service-to-service requests
+	r.Use(middleware.AuthenticateAPIKey(middleware.NewSQLAPIKeys(db)))
+
+	// Health, readiness and build information for the container healthchecks and nginx
+	checks := health.New(app.Ready, cfg.Health.Timeout)
+	checks.Add("database", health.Database(db))
+	checks.Add("schema", health.SchemaVersion(db, databases.SchemaVersion))
+	for _, url := range cfg.Health.Dependencies {
+		checks.Add(url, health.Dependency(http.DefaultClient, url))
+	}
+	checks.Register(r)
+
+	// Prometheus metrics of the requests, the connection pool and the domain events
+	metrics.RegisterDB(db)
+	handlers.RegisterPrizePoolMetric(db)
+	metrics.Register(r)
+
 	docs.SwaggerInfo.BasePath = "/api/v1"
 
 	// Setup Challenges routes
-	handlers.SetupChallengeRoutes(r.Group("/challenges"), db)
+	handlers.SetupChallengeRoutes(r.Group("/challenges"), db, limiter, app.Workers)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 
-	fmt.Println("Starting Go API service...")
+	slog.Info("starting the server", "port", cfg.Port)
 
-	fmt.Println(`
+	//The banner would break the JSON lines of the logs
+	if cfg.Log.Format == "text" {
+		fmt.Println(`
 	______     ______        ______     ______   __    
    /\  ___\   /\  __ \      /\  __ \   /\  == \ /\ \   
    \ \ \__ \  \ \ \/\ \     \ \  __ \  \ \  _-/ \ \ \  
 	\ \_____\  \ \_____\     \ \_\ \_\  \ \_\    \ \_\ 
 	 \/_____/   \/_____/      \/_/\/_/   \/_/     \/_/ `)
+	}
 
-	// Run with port
-	r.Run(os.Getenv("PORT"))
+	// Run with port until SIGINT or SIGTERM, then drain the requests and stop the workers
+	server := &http.Server{Addr: cfg.Port, Handler: r}
+	runErr := app.Run(server, cfg.Shutdown.Delay, cfg.Shutdown.DrainTimeout)
+
+	// Flush the spans of the last requests before exiting
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("error flushing the traces", "error", err)
+	}
+	if runErr != nil {
+		logging.Fatal("error running the server", runErr)
+	}
 }
@@ -28,9 +28,9 @@ type Challenge struct {
 	Probability float64   `json:"probability" binding:"required"`
 }
 
-// New Challenge Struct for request
+// New Challenge Struct for request, player_id defaults to the authenticated player
 type NewChallengeNeed struct {
-	PlayerID int     `json:"player_id" binding:"required"`
+	PlayerID int     `json:"player_id"`
 	Amount   float64 `json:"amount" binding:"required" validate:"eq=20.01"`
 }
 
@@ -39,7 +39,9 @@ type JoinChallengeResponse struct {
 	Status Status `json:"status"`
 }
 
-// ErrorResponse represents an error response with a single error message.
+// ErrorResponse represents an error response with a single error message and
+// the ID of the request, to find its logs.
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
 }
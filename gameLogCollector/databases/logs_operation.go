@@ -1,14 +1,18 @@
 package databases
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
 
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameLogCollector/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
 )
 
-func ListLogs(db *sql.DB, playerID int, action string, startTime time.Time, endTime time.Time, limit int) ([]models.GameLog, error) {
+func ListLogs(ctx context.Context, db *sql.DB, playerID int, action string, startTime time.Time, endTime time.Time, limit int) ([]models.GameLog, error) {
+	ctx, span := tracing.Start(ctx, "databases.ListLogs")
+	defer span.End()
 
 	query := `
 		SELECT 
@@ -39,7 +43,7 @@ func ListLogs(db *sql.DB, playerID int, action string, startTime time.Time, endT
 		args = append(args, limit)
 	}
 
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error querying database with ListLogs: %w", err)
 	}
@@ -63,8 +67,11 @@ func ListLogs(db *sql.DB, playerID int, action string, startTime time.Time, endT
 	return logs, nil
 }
 
-func AddLog(db *sql.DB, log models.GameLog) (int, error) {
-	result, err := db.Exec(`
+func AddLog(ctx context.Context, db *sql.DB, log models.GameLog) (int, error) {
+	ctx, span := tracing.Start(ctx, "databases.AddLog")
+	defer span.End()
+
+	result, err := db.ExecContext(ctx, `
 		INSERT INTO GameLog (PlayerID, Action, Timestamp, Details) 
 		VALUES (?, ?, ?, ?)
 	`, log.PlayerID, log.Action, time.Now(), log.Details)
@@ -9,6 +9,7 @@ const docTemplate = `{
     "info": {
         "description": "{{escape .Description}}",
         "title": "{{.Title}}",
+        "termsOfService": "http://swagger.io/terms/",
         "contact": {
             "name": "Steven Poon",
             "url": "https://github.com/RYANCOAL9999",
@@ -25,7 +26,15 @@ const docTemplate = `{
     "paths": {
         "/game_logs": {
             "get": {
-                "description": "Fetches a list of game logs, allowing optional filtering by player ID, action, start time, end time, and limit. If more than one log is found, returns the first log. Returns a list of logs otherwise.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Fetches a list of game logs, allowing optional filtering by player ID, action, start time, end time, and limit. Players must filter by their own player ID, reading the logs of others needs the game_logs:read permission. If more than one log is found, returns the first log. Returns a list of logs otherwise.",
                 "consumes": [
                     "application/json"
                 ],
@@ -84,6 +93,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Logs of other players without permission game_logs:read",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -93,7 +114,15 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Adds a new game log entry with the provided details. The request body must contain the player ID, action, timestamp, and details. Returns the ID of the newly created log entry if successful.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds a new game log entry with the provided details. The request body must contain the player ID, action, timestamp, and details. Returns the ID of the newly created log entry if successful. Players can only log their own actions, logging for others needs the game_logs:write permission.",
                 "consumes": [
                     "application/json"
                 ],
@@ -128,6 +157,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Log for another player without permission game_logs:write",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -136,9 +177,127 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Reports that the process is alive, it does not check any dependency.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness",
+                "responses": {
+                    "200": {
+                        "description": "The process is alive",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Runs the readiness checks of the service, such as the database, the schema version and the services it depends on, with the status and latency of each.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness",
+                "responses": {
+                    "200": {
+                        "description": "Every check passed",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "A check failed or the service is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "Git commit and build time of the binary and the Go version it was built with.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Build information",
+                "responses": {
+                    "200": {
+                        "description": "Build information",
+                        "schema": {
+                            "$ref": "#/definitions/health.Version"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
+        "health.CheckResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "number",
+                    "example": 1.25
+                },
+                "name": {
+                    "type": "string",
+                    "example": "database"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/health.CheckResult"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "health.Version": {
+            "type": "object",
+            "properties": {
+                "build_time": {
+                    "type": "string",
+                    "example": "2024-07-27T16:09:21Z"
+                },
+                "commit": {
+                    "type": "string",
+                    "example": "9fd67d9"
+                },
+                "go_version": {
+                    "type": "string",
+                    "example": "go1.20.14"
+                }
+            }
+        },
         "models.CreateResponse": {
             "type": "object",
             "properties": {
@@ -152,6 +311,9 @@ const docTemplate = `{
             "properties": {
                 "error": {
                     "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
                 }
             }
         },
@@ -180,6 +342,20 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key of another service from POST /api_keys of playerManagementSystem",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "Access token from POST /auth/login of playerManagementSystem, as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -3,11 +3,19 @@ package handlers
 import (
 	"database/sql"
 
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameLogCollector/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupLogsRoutes(logs *gin.RouterGroup, db *sql.DB) {
+// errorResponse is the body of a failed request, carrying its ID to find its logs.
+func errorResponse(c *gin.Context, message string) models.ErrorResponse {
+	return models.ErrorResponse{Error: message, RequestID: middleware.RequestID(c)}
+}
+
+func SetupLogsRoutes(logs *gin.RouterGroup, db *sql.DB, limiter middleware.RateLimitStore) {
 	// Player routes
-	logs.POST("/", func(c *gin.Context) { CreateLog(c, db) })
-	logs.GET("/", func(c *gin.Context) { GetLogs(c, db) })
+	logs.Use(middleware.RateLimit(limiter, DefaultRateLimit))
+	logs.POST("/", middleware.RequireAuth(), func(c *gin.Context) { CreateLog(c, db) })
+	logs.GET("/", middleware.RequireAuth(), func(c *gin.Context) { GetLogs(c, db) })
 }
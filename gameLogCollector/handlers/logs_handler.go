@@ -8,13 +8,14 @@ import (
 
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameLogCollector/databases"
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameLogCollector/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
 	"github.com/gin-gonic/gin"
 )
 
 const time_format string = "2006-01-02 00:00:00"
 
 // @Summary      Retrieve game logs
-// @Description  Fetches a list of game logs, allowing optional filtering by player ID, action, start time, end time, and limit. If more than one log is found, returns the first log. Returns a list of logs otherwise.
+// @Description  Fetches a list of game logs, allowing optional filtering by player ID, action, start time, end time, and limit. Players must filter by their own player ID, reading the logs of others needs the game_logs:read permission. If more than one log is found, returns the first log. Returns a list of logs otherwise.
 // @Tags         game_logs
 // @Accept       json
 // @Produce      json
@@ -25,7 +26,11 @@ const time_format string = "2006-01-02 00:00:00"
 // @Param        limit      query  int     false  "Limit the number of logs returned"
 // @Success      200  {object}  []models.GameLog  "List of game logs matching the criteria"
 // @Failure      400  {object}  models.ErrorResponse "Bad request due to invalid query parameters"
+// @Failure      401  {object}  models.ErrorResponse "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse "Logs of other players without permission game_logs:read"
 // @Failure      500  {object}  models.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /game_logs [get]
 func GetLogs(c *gin.Context, db *sql.DB) {
 	var args interface{}
@@ -34,9 +39,14 @@ func GetLogs(c *gin.Context, db *sql.DB) {
 	startTime, _ := time.Parse(time_format, c.Query("start_time"))
 	endTime, _ := time.Parse(time_format, c.Query("end_time"))
 	limit, _ := strconv.Atoi(c.Query("limit"))
-	logs, err := databases.ListLogs(db, playerID, action, startTime, endTime, limit)
+	// Players can only read their own logs
+	if !middleware.IsPlayer(c, playerID) && !Policy.Allows(c, PermLogsRead) {
+		c.JSON(http.StatusForbidden, errorResponse(c, "missing permission "+PermLogsRead+" to read the logs of other players"))
+		return
+	}
+	logs, err := databases.ListLogs(c.Request.Context(), db, playerID, action, startTime, endTime, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
 	if len(logs) <= 1 {
@@ -48,26 +58,36 @@ func GetLogs(c *gin.Context, db *sql.DB) {
 }
 
 // @Summary      Create a game log
-// @Description  Adds a new game log entry with the provided details. The request body must contain the player ID, action, timestamp, and details. Returns the ID of the newly created log entry if successful.
+// @Description  Adds a new game log entry with the provided details. The request body must contain the player ID, action, timestamp, and details. Returns the ID of the newly created log entry if successful. Players can only log their own actions, logging for others needs the game_logs:write permission.
 // @Tags         game_logs
 // @Accept       json
 // @Produce      json
 // @Param        game_log  body models.GameLog  true  "Details of the game log to be created"
 // @Success      201  {object}  models.CreateResponse "Game log created successfully, returns the ID of the new game log"
 // @Failure      400  {object}  models.ErrorResponse "Bad request due to invalid input data"
+// @Failure      401  {object}  models.ErrorResponse "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse "Log for another player without permission game_logs:write"
 // @Failure      500  {object}  models.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /game_logs [post]
 func CreateLog(c *gin.Context, db *sql.DB) {
 	var newLog models.GameLog
 	if err := c.BindJSON(&newLog); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+	// Players can only log their own actions
+	if !middleware.IsPlayer(c, newLog.PlayerID) && !Policy.Allows(c, PermLogsWrite) {
+		c.JSON(http.StatusForbidden, errorResponse(c, "missing permission "+PermLogsWrite+" to log for other players"))
 		return
 	}
 
-	id, err := databases.AddLog(db, newLog)
+	id, err := databases.AddLog(c.Request.Context(), db, newLog)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
+	logsIngested.Inc()
 	c.JSON(http.StatusCreated, models.CreateResponse{ID: id})
 }
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Domain metrics of the game logs routes.
+var (
+	logsIngested = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "game_logs_ingested_total",
+		Help:      "Game logs stored.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(logsIngested)
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
+)
+
+// Permissions of the game logs routes.
+const (
+	PermLogsRead  = "game_logs:read"
+	PermLogsWrite = "game_logs:write"
+)
+
+// Policy grants the permissions to roles, players can always read and write their own logs.
+var Policy = middleware.Policy{
+	PermLogsRead:  {middleware.RoleSupport, middleware.RoleGameMaster, middleware.RoleAdmin},
+	PermLogsWrite: {middleware.RoleGameMaster, middleware.RoleAdmin},
+}
+
+// Rate limits of the routes, game clients send logs in bursts so the default is higher than elsewhere.
+var (
+	DefaultRateLimit = middleware.RateLimitPolicy{Name: "game_logs", Limit: 600, Window: time.Minute}
+)
@@ -13,9 +13,11 @@ type GameLog struct {
 	Details   string    `json:"details" binding:"required"`
 }
 
-// ErrorResponse represents an error response with a single error message.
+// ErrorResponse represents an error response with a single error message and
+// the ID of the request, to find its logs.
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // CreateResponse represents an id after created a item.
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidRoomSecret is returned when the invite code or password of a private room does not match.
+var ErrInvalidRoomSecret = errors.New("invalid invite code or password")
+
+// HashPassword returns the bcrypt hash of a room password.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// NewInviteCode returns a random invite code of a private room.
+func NewInviteCode() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating invite code: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CheckRoomSecret accepts the invite code or the password of a private room,
+// the password only when the room has one.
+func CheckRoomSecret(inviteCode, passwordHash, givenCode, givenPassword string) error {
+	if givenCode != "" && inviteCode != "" && subtle.ConstantTimeCompare([]byte(givenCode), []byte(inviteCode)) == 1 {
+		return nil
+	}
+	if givenPassword != "" && passwordHash != "" &&
+		bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(givenPassword)) == nil {
+		return nil
+	}
+	return ErrInvalidRoomSecret
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	shared "github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/config"
+)
+
+// Config is the configuration of gameRoomManagementSystem.
+type Config struct {
+	Port      string           `config:"port" env:"PORT" default:":8083"`
+	Database  shared.Database  `config:"database"`
+	Auth      Auth             `config:"auth"`
+	RateLimit shared.RateLimit `config:"rate_limit"`
+	Shutdown  shared.Shutdown  `config:"shutdown"`
+	Health    shared.Health    `config:"health"`
+	Tracing   shared.Tracing   `config:"tracing"`
+	Log       shared.Log       `config:"log"`
+
+	Reservations Reservations `config:"reservations"`
+}
+
+// Auth verifies the access tokens issued by playerManagementSystem.
+type Auth struct {
+	JWTSecret shared.Secret `config:"jwt_secret" env:"JWT_SECRET"`
+	JWKSFile  string        `config:"jwks_file" env:"JWT_JWKS_FILE"`
+}
+
+// Reservations keeps Buffer free between two reservations of a room, to
+// clean it and let the next group in, the check-in opens then. The
+// reservations not checked in NoShowAfter their start are marked as no shows
+// every SweepInterval.
+type Reservations struct {
+	Buffer        time.Duration `config:"buffer" env:"RESERVATION_BUFFER" default:"15m"`
+	NoShowAfter   time.Duration `config:"no_show_after" env:"RESERVATION_NO_SHOW_AFTER" default:"15m"`
+	SweepInterval time.Duration `config:"sweep_interval" env:"RESERVATION_SWEEP_INTERVAL" default:"1m"`
+}
+
+func (r Reservations) Validate() error {
+	if r.Buffer < 0 || r.NoShowAfter < 0 {
+		return fmt.Errorf("RESERVATION_BUFFER and RESERVATION_NO_SHOW_AFTER must not be negative")
+	}
+	if r.SweepInterval <= 0 {
+		return fmt.Errorf("RESERVATION_SWEEP_INTERVAL must be positive")
+	}
+	return nil
+}
+
+func (c *Config) Validate() error {
+	if err := c.Database.Validate(); err != nil {
+		return err
+	}
+	if c.Auth.JWTSecret == "" && c.Auth.JWKSFile == "" {
+		return fmt.Errorf("JWT_SECRET or JWT_JWKS_FILE is required")
+	}
+	if c.Auth.JWTSecret.Placeholder() {
+		return fmt.Errorf("JWT_SECRET is a placeholder, set it to the secret of playerManagementSystem")
+	}
+	if err := c.RateLimit.Validate(); err != nil {
+		return err
+	}
+	if err := c.Shutdown.Validate(); err != nil {
+		return err
+	}
+	if err := c.Health.Validate(); err != nil {
+		return err
+	}
+	if err := c.Tracing.Validate(); err != nil {
+		return err
+	}
+	if err := c.Log.Validate(); err != nil {
+		return err
+	}
+	return c.Reservations.Validate()
+}
+
+// Load fills cfg from the environment, the .env file, the YAML or TOML file at
+// path and the defaults, then validates it, see the config package of shared.
+func Load(cfg *Config, path string) error {
+	return shared.Load(cfg, path)
+}
+
+// Print writes cfg with its secrets redacted.
+func Print(w io.Writer, cfg *Config) error {
+	return shared.Print(w, cfg)
+}
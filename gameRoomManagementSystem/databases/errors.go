@@ -0,0 +1,42 @@
+package databases
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	// ErrRoomNotFound is returned when no room has the requested ID.
+	ErrRoomNotFound = errors.New("room not found")
+	// ErrPlayerNotFound is returned when the player to add to a room does not exist or is deleted.
+	ErrPlayerNotFound = errors.New("player not found")
+	// ErrAlreadyInRoom is returned when adding a player who is already in the room.
+	ErrAlreadyInRoom = errors.New("player is already in the room")
+	// ErrRoomFull is returned when the room already has as many players as its capacity.
+	ErrRoomFull = errors.New("room is full")
+	// ErrLevelOutOfRange is returned when the LV of the player is outside the range of the room.
+	ErrLevelOutOfRange = errors.New("player lv is outside the range of the room")
+	// ErrTooFewPlayers is returned when a reservation has fewer players than the room needs.
+	ErrTooFewPlayers = errors.New("too few players for the room")
+	// ErrIllegalTransition is returned when the status of a room does not allow the event.
+	ErrIllegalTransition = errors.New("illegal room transition")
+	// ErrSlotTaken is returned when a reservation overlaps another one of the room, or its buffer.
+	ErrSlotTaken = errors.New("time slot is already reserved")
+	// ErrRoomClosed is returned when reserving a closed room.
+	ErrRoomClosed = errors.New("room is closed")
+	// ErrReservationNotFound is returned when no reservation has the requested ID.
+	ErrReservationNotFound = errors.New("reservation not found")
+	// ErrReservationStatus is returned when the status of a reservation does not allow the change.
+	ErrReservationStatus = errors.New("reservation status does not allow it")
+	// ErrCheckInWindow is returned when checking in a reservation too early or too late.
+	ErrCheckInWindow = errors.New("reservation is outside its check-in window")
+)
+
+// mysqlDuplicateEntry is the MySQL error number for a unique key violation.
+const mysqlDuplicateEntry = 1062
+
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry
+}
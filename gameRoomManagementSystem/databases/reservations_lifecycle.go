@@ -0,0 +1,323 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
+)
+
+// reservationColumns are the columns of models.ReservationRoom read by scanReservation.
+const reservationColumns = `R.ID, R.RoomID, R.StartTime, R.EndTime, R.Status, R.PlayerID, R.CheckedInAt`
+
+// holdsSlot selects the reservations holding their time slot, the cancelled
+// and no show ones leave it to others.
+const holdsSlot = `R.Status IN ('pending', 'confirmed', 'checked_in')`
+
+func scanReservation(row scanner, r *models.ReservationRoom) error {
+	var createdBy sql.NullInt64
+	var checkedInAt sql.NullTime
+	err := row.Scan(
+		&r.ID,
+		&r.RoomID,
+		&r.StartTime,
+		&r.EndTime,
+		&r.Status,
+		&createdBy,
+		&checkedInAt,
+	)
+	if err != nil {
+		return err
+	}
+	r.CreatedBy = nullInt(createdBy)
+	if checkedInAt.Valid {
+		r.CheckedInAt = &checkedInAt.Time
+	}
+	return nil
+}
+
+// ShowReservation returns the reservation with its players.
+func ShowReservation(ctx context.Context, db *sql.DB, id int) (*models.ReservationRoom, error) {
+	ctx, span := tracing.Start(ctx, "databases.ShowReservation")
+	defer span.End()
+
+	var r models.ReservationRoom
+	err := scanReservation(db.QueryRowContext(ctx, `SELECT `+reservationColumns+` FROM Reservation R WHERE R.ID = ?`, id), &r)
+	if err == sql.ErrNoRows {
+		return nil, ErrReservationNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("error querying database with ShowReservation: %w", err)
+	}
+
+	players, err := reservationPlayers(ctx, db, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	r.Player = players[r.ID]
+	return &r, nil
+}
+
+// reservationPlayers returns the players of the reservations with their level
+// by reservation ID, in one query whatever the number of reservations. The
+// deleted players are left out.
+func reservationPlayers(ctx context.Context, db *sql.DB, ids ...int) (map[int][]models.PlayerRank, error) {
+	playerRanks := map[int][]models.PlayerRank{}
+	if len(ids) == 0 {
+		return playerRanks, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := db.QueryContext(ctx, `
+		SELECT
+		RP.ReservationID, P.ID, P.Name, L.LV
+		FROM ReservationPlayer RP
+		INNER JOIN Player P ON P.ID = RP.PlayerID
+		INNER JOIN Level L ON P.LevelID = L.ID
+		WHERE RP.ReservationID IN (?`+strings.Repeat(", ?", len(ids)-1)+`) AND P.DeletedAt IS NULL
+		ORDER BY RP.ReservationID, P.ID
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying database with reservationPlayers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var reservationID int
+		var playerRank models.PlayerRank
+		err := rows.Scan(
+			&reservationID,
+			&playerRank.ID,
+			&playerRank.Name,
+			&playerRank.LV,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row with reservationPlayers: %w", err)
+		}
+		playerRanks[reservationID] = append(playerRanks[reservationID], playerRank)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows with reservationPlayers: %w", err)
+	}
+
+	return playerRanks, nil
+}
+
+// lockReservation locks the room of the reservation, then the reservation,
+// in the order InsertReservation locks them.
+func lockReservation(ctx context.Context, tx *sql.Tx, id int) (*models.ReservationRoom, *models.Room, error) {
+	var roomID int
+	err := tx.QueryRowContext(ctx, `SELECT RoomID FROM Reservation WHERE ID = ?`, id).Scan(&roomID)
+	if err == sql.ErrNoRows {
+		return nil, nil, ErrReservationNotFound
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("error querying reservation with lockReservation: %w", err)
+	}
+
+	room, err := lockRoom(ctx, tx, roomID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var r models.ReservationRoom
+	err = scanReservation(tx.QueryRowContext(ctx, `SELECT `+reservationColumns+` FROM Reservation R WHERE R.ID = ? FOR UPDATE`, id), &r)
+	if err == sql.ErrNoRows {
+		return nil, nil, ErrReservationNotFound
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("error locking reservation: %w", err)
+	}
+	return &r, room, nil
+}
+
+// setReservationStatus updates the status of a reservation locked by tx.
+func setReservationStatus(ctx context.Context, tx *sql.Tx, id int, status models.ReservationStatus) error {
+	_, err := tx.ExecContext(ctx, `UPDATE Reservation SET Status = ? WHERE ID = ?`, status, id)
+	if err != nil {
+		return fmt.Errorf("error updating reservation status: %w", err)
+	}
+	return nil
+}
+
+// RescheduleReservation moves an upcoming reservation to another time slot of
+// its room, which must not overlap the other reservations of the room nor
+// their buffer.
+func RescheduleReservation(ctx context.Context, db *sql.DB, id int, slot models.TimeSlot, buffer time.Duration) error {
+	ctx, span := tracing.Start(ctx, "databases.RescheduleReservation")
+	defer span.End()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction with RescheduleReservation: %w", err)
+	}
+	defer tx.Rollback()
+
+	r, _, err := lockReservation(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if !r.Status.Upcoming() {
+		return fmt.Errorf("%w: a reservation that is %s cannot be rescheduled", ErrReservationStatus, r.Status)
+	}
+	if err := checkSlot(ctx, tx, r.RoomID, slot, buffer, id); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE Reservation SET StartTime = ?, EndTime = ?
+		WHERE ID = ?
+	`, slot.StartTime, slot.EndTime, id)
+	if err != nil {
+		return fmt.Errorf("error querying database with RescheduleReservation: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction with RescheduleReservation: %w", err)
+	}
+	return nil
+}
+
+// ConfirmReservation confirms a pending reservation.
+func ConfirmReservation(ctx context.Context, db *sql.DB, id int) error {
+	ctx, span := tracing.Start(ctx, "databases.ConfirmReservation")
+	defer span.End()
+
+	return changeReservation(ctx, db, id, func(tx *sql.Tx, r *models.ReservationRoom, room *models.Room) error {
+		if r.Status != models.ReservationPending {
+			return fmt.Errorf("%w: a reservation that is %s cannot be confirmed", ErrReservationStatus, r.Status)
+		}
+		return setReservationStatus(ctx, tx, id, models.ReservationConfirmed)
+	})
+}
+
+// CancelReservation cancels an upcoming reservation, its time slot is free again.
+func CancelReservation(ctx context.Context, db *sql.DB, id int) error {
+	ctx, span := tracing.Start(ctx, "databases.CancelReservation")
+	defer span.End()
+
+	return changeReservation(ctx, db, id, func(tx *sql.Tx, r *models.ReservationRoom, room *models.Room) error {
+		if !r.Status.Upcoming() {
+			return fmt.Errorf("%w: a reservation that is %s cannot be cancelled", ErrReservationStatus, r.Status)
+		}
+		return setReservationStatus(ctx, tx, id, models.ReservationCancelled)
+	})
+}
+
+// CheckInReservation checks in an upcoming reservation and occupies its room.
+// The check-in opens opensBefore the start of the reservation and closes
+// noShowAfter it. The reservations of the room that ended are completed first
+// so their room is released.
+func CheckInReservation(ctx context.Context, db *sql.DB, id int, now time.Time, opensBefore, noShowAfter time.Duration, actor string) error {
+	ctx, span := tracing.Start(ctx, "databases.CheckInReservation")
+	defer span.End()
+
+	return changeReservation(ctx, db, id, func(tx *sql.Tx, r *models.ReservationRoom, room *models.Room) error {
+		if !r.Status.Upcoming() {
+			return fmt.Errorf("%w: a reservation that is %s cannot be checked in", ErrReservationStatus, r.Status)
+		}
+		if opens := r.StartTime.Add(-opensBefore); now.Before(opens) {
+			return fmt.Errorf("%w: it opens at %s", ErrCheckInWindow, opens.Format(time.RFC3339))
+		}
+		if closes := r.StartTime.Add(noShowAfter); now.After(closes) {
+			return fmt.Errorf("%w: it closed at %s", ErrCheckInWindow, closes.Format(time.RFC3339))
+		}
+
+		if err := completeEndedReservations(ctx, tx, room, now, actor); err != nil {
+			return err
+		}
+		reason := fmt.Sprintf("check-in of reservation %d", id)
+		if _, err := transitionRoom(ctx, tx, r.RoomID, models.EventOccupy, actor, reason); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			UPDATE Reservation SET Status = ?, CheckedInAt = ?
+			WHERE ID = ?
+		`, models.ReservationCheckedIn, now, id)
+		if err != nil {
+			return fmt.Errorf("error updating reservation with CheckInReservation: %w", err)
+		}
+		return nil
+	})
+}
+
+// changeReservation runs change on the reservation and its room, both locked,
+// and commits when it succeeds.
+func changeReservation(ctx context.Context, db *sql.DB, id int, change func(tx *sql.Tx, r *models.ReservationRoom, room *models.Room) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction with changeReservation: %w", err)
+	}
+	defer tx.Rollback()
+
+	r, room, err := lockReservation(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := change(tx, r, room); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction with changeReservation: %w", err)
+	}
+	return nil
+}
+
+// completeEndedReservations completes the checked in reservations of a room
+// locked by tx which ended by now, and releases the room they occupy.
+func completeEndedReservations(ctx context.Context, tx *sql.Tx, room *models.Room, now time.Time, actor string) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT R.ID
+		FROM Reservation R
+		WHERE R.RoomID = ? AND R.Status = ? AND R.EndTime <= ?
+		ORDER BY R.ID
+		FOR UPDATE
+	`, room.ID, models.ReservationCheckedIn, now)
+	if err != nil {
+		return fmt.Errorf("error querying reservations with completeEndedReservations: %w", err)
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning row with completeEndedReservations: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating over rows with completeEndedReservations: %w", err)
+	}
+
+	for _, id := range ids {
+		if err := completeReservation(ctx, tx, id, room, actor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// completeReservation completes a checked in reservation locked by tx and
+// releases its room, unless the room was already released or put under
+// maintenance or closed meanwhile.
+func completeReservation(ctx context.Context, tx *sql.Tx, id int, room *models.Room, actor string) error {
+	if err := setReservationStatus(ctx, tx, id, models.ReservationCompleted); err != nil {
+		return err
+	}
+	reason := fmt.Sprintf("reservation %d completed", id)
+	transition, err := transitionRoom(ctx, tx, room.ID, models.EventRelease, actor, reason)
+	if err != nil && !errors.Is(err, ErrIllegalTransition) {
+		return err
+	}
+	if transition != nil {
+		room.Status = transition.To
+	}
+	return nil
+}
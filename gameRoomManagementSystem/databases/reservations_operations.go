@@ -1,31 +1,21 @@
 package databases
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
 )
 
-func AizuArray(A string) []int {
-	strs := strings.Split(A, ", ")
-	ary := make([]int, len(strs))
-	for i := range ary {
-		ary[i], _ = strconv.Atoi(strs[i])
-	}
-	return ary
-}
+func ListReservation(ctx context.Context, db *sql.DB, roomID int, startDate, endDate time.Time, limit int) ([]models.ReservationRoom, error) {
+	ctx, span := tracing.Start(ctx, "databases.ListReservation")
+	defer span.End()
 
-func ListReservation(db *sql.DB, roomID int, startDate, endDate time.Time, limit int) ([]models.ReservationRoom, error) {
 	query := `
-        SELECT
-		R.ID AS ReservationID,
-		RM.ID AS RoomID,
-		R.Date AS ReservationDate,
-		RM.PlayerIDs AS PlayerIDs
+        SELECT ` + reservationColumns + `
         FROM Reservation R
         INNER JOIN Room RM ON R.RoomID = RM.ID
         WHERE 1 = 1
@@ -33,23 +23,23 @@ func ListReservation(db *sql.DB, roomID int, startDate, endDate time.Time, limit
 	args := []interface{}{}
 
 	if roomID != 0 {
-		query += " AND RoomID = ?"
+		query += " AND R.RoomID = ?"
 		args = append(args, roomID)
 	}
 
 	if !startDate.IsZero() && !endDate.IsZero() {
-		query += " AND ReservationDate BETWEEN ? AND ?"
+		query += " AND R.StartTime BETWEEN ? AND ?"
 		args = append(args, startDate, endDate)
 	}
 
-	query += " ORDER BY ReservationID"
+	query += " ORDER BY R.ID"
 
 	if limit > 0 {
 		query += " LIMIT ?"
 		args = append(args, limit)
 	}
 
-	rows, err := db.Query(query, args...)
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error querying database with ListReservation: %w", err)
 	}
@@ -58,41 +48,194 @@ func ListReservation(db *sql.DB, roomID int, startDate, endDate time.Time, limit
 	var reservations []models.ReservationRoom
 	for rows.Next() {
 		var r models.ReservationRoom
-		var playerIDsStr string
-		err := rows.Scan(
-			&r.ID,
-			&r.RoomID,
-			&r.Date,
-			&playerIDsStr,
-		)
-		if err != nil {
+		if err := scanReservation(rows, &r); err != nil {
 			return nil, fmt.Errorf("error scanning row with ListReservation: %w", err)
 		}
-
-		playerIDs := AizuArray(playerIDsStr)
-
-		r.Player, _ = SearchPlayerInRoom(db, playerIDs)
-
 		reservations = append(reservations, r)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating over rows with ListReservation: %w", err)
 	}
+	rows.Close()
+
+	ids := make([]int, len(reservations))
+	for i, r := range reservations {
+		ids[i] = r.ID
+	}
+	players, err := reservationPlayers(ctx, db, ids...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range reservations {
+		reservations[i].Player = players[reservations[i].ID]
+	}
 
 	return reservations, nil
 }
 
-// insertReservation function
-func InsertReservation(db *sql.DB, roomID int, date time.Time) (int, error) {
-	result, err := db.Exec(`
-		INSERT INTO Reservation (RoomID, Date) 
-		VALUES (?, ?)
-	`, roomID, date)
+// InsertReservation inserts the pending reservation of the time slot, made by
+// the player createdBy or 0 with an API key, with its players. The players
+// are checked as they join the room, and there are at most its capacity, but
+// they are only in the room for the time slot so they are kept apart from
+// RoomPlayer. The slot must not overlap another reservation of the room, nor
+// the buffer kept around it. The room row is locked so two reservations of
+// the same slot cannot both be made.
+func InsertReservation(ctx context.Context, db *sql.DB, roomID int, slot models.TimeSlot, buffer time.Duration, playerIDs []int, createdBy int) (int, error) {
+	ctx, span := tracing.Start(ctx, "databases.InsertReservation")
+	defer span.End()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction with InsertReservation: %w", err)
+	}
+	defer tx.Rollback()
+
+	room, err := lockRoom(ctx, tx, roomID)
+	if err != nil {
+		return 0, err
+	}
+	if room.Status == models.StatusClosed {
+		return 0, ErrRoomClosed
+	}
+
+	if err := checkSlot(ctx, tx, roomID, slot, buffer, 0); err != nil {
+		return 0, err
+	}
+
+	var players []int
+	seen := map[int]bool{}
+	for _, playerID := range playerIDs {
+		if !seen[playerID] {
+			seen[playerID] = true
+			players = append(players, playerID)
+		}
+	}
+	if len(players) < room.MinPlayers {
+		return 0, fmt.Errorf("%w: it needs %d players", ErrTooFewPlayers, room.MinPlayers)
+	}
+	if room.Capacity > 0 && len(players) > room.Capacity {
+		return 0, fmt.Errorf("%w: it holds %d players", ErrRoomFull, room.Capacity)
+	}
+	for _, playerID := range players {
+		lv, err := playerLV(ctx, tx, playerID)
+		if err != nil {
+			return 0, err
+		}
+		if err := checkLevel(room, playerID, lv); err != nil {
+			return 0, err
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO Reservation (RoomID, StartTime, EndTime, Status, PlayerID)
+		VALUES (?, ?, ?, ?, NULLIF(?, 0))
+	`, roomID, slot.StartTime, slot.EndTime, models.ReservationPending, createdBy)
 	if err != nil {
 		return 0, fmt.Errorf("error querying database with InsertReservation: %w", err)
 	}
 	id, _ := result.LastInsertId()
-	return int(id), err
 
+	for _, playerID := range players {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO ReservationPlayer (ReservationID, PlayerID)
+			VALUES (?, ?)
+		`, id, playerID)
+		if err != nil {
+			return 0, fmt.Errorf("error inserting reservation player with InsertReservation: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing transaction with InsertReservation: %w", err)
+	}
+	return int(id), nil
+}
+
+// RoomAvailability returns the free time slots of the room between from and
+// to, the periods left by the reservations holding a slot and their buffer. A
+// closed room has none.
+func RoomAvailability(ctx context.Context, db *sql.DB, roomID int, from, to time.Time, buffer time.Duration) ([]models.TimeSlot, error) {
+	ctx, span := tracing.Start(ctx, "databases.RoomAvailability")
+	defer span.End()
+
+	var status models.Status
+	err := db.QueryRowContext(ctx, `SELECT Status FROM Room WHERE ID = ?`, roomID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil, ErrRoomNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("error querying database with RoomAvailability: %w", err)
+	}
+	if status == models.StatusClosed {
+		return []models.TimeSlot{}, nil
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT R.StartTime, R.EndTime
+		FROM Reservation R
+		WHERE R.RoomID = ? AND R.StartTime < ? AND R.EndTime > ? AND `+holdsSlot+`
+		ORDER BY R.StartTime
+	`, roomID, to.Add(buffer), from.Add(-buffer))
+	if err != nil {
+		return nil, fmt.Errorf("error querying database with RoomAvailability: %w", err)
+	}
+	defer rows.Close()
+
+	var reserved []models.TimeSlot
+	for rows.Next() {
+		var slot models.TimeSlot
+		if err := rows.Scan(&slot.StartTime, &slot.EndTime); err != nil {
+			return nil, fmt.Errorf("error scanning row with RoomAvailability: %w", err)
+		}
+		reserved = append(reserved, slot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows with RoomAvailability: %w", err)
+	}
+	return freeSlots(from, to, reserved, buffer), nil
+}
+
+// freeSlots returns the periods between from and to left by the reserved
+// slots, ordered by start time, and the buffer around each of them.
+func freeSlots(from, to time.Time, reserved []models.TimeSlot, buffer time.Duration) []models.TimeSlot {
+	free := []models.TimeSlot{}
+	start := from
+	for _, slot := range reserved {
+		// The slots up to the buffer before the reservation are free
+		if end := minTime(slot.StartTime.Add(-buffer), to); end.After(start) {
+			free = append(free, models.TimeSlot{StartTime: start, EndTime: end})
+		}
+		if next := slot.EndTime.Add(buffer); next.After(start) {
+			start = next
+		}
+	}
+	if start.Before(to) {
+		free = append(free, models.TimeSlot{StartTime: start, EndTime: to})
+	}
+	return free
+}
+
+// checkSlot returns ErrSlotTaken when the time slot, with the buffer around
+// it, overlaps a reservation of the room other than except.
+func checkSlot(ctx context.Context, tx *sql.Tx, roomID int, slot models.TimeSlot, buffer time.Duration, except int) error {
+	var reservationID int
+	err := tx.QueryRowContext(ctx, `
+		SELECT R.ID
+		FROM Reservation R
+		WHERE R.RoomID = ? AND R.ID <> ? AND R.StartTime < ? AND R.EndTime > ? AND `+holdsSlot+`
+		LIMIT 1
+	`, roomID, except, slot.EndTime.Add(buffer), slot.StartTime.Add(-buffer)).Scan(&reservationID)
+	if err == nil {
+		return fmt.Errorf("%w: it overlaps reservation %d", ErrSlotTaken, reservationID)
+	} else if err != sql.ErrNoRows {
+		return fmt.Errorf("error querying reservations with checkSlot: %w", err)
+	}
+	return nil
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
 }
@@ -0,0 +1,201 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/models"
+)
+
+func slot(start, end time.Time) models.TimeSlot {
+	return models.TimeSlot{StartTime: start, EndTime: end}
+}
+
+func TestFreeSlots(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.UTC)
+	}
+	from, to := at(9, 0), at(17, 0)
+
+	tests := []struct {
+		name     string
+		reserved []models.TimeSlot
+		buffer   time.Duration
+		want     []models.TimeSlot
+	}{
+		{
+			name: "no reservations",
+			want: []models.TimeSlot{slot(from, to)},
+		},
+		{
+			name:     "gap around a reservation",
+			reserved: []models.TimeSlot{slot(at(11, 0), at(12, 0))},
+			want:     []models.TimeSlot{slot(from, at(11, 0)), slot(at(12, 0), to)},
+		},
+		{
+			name:     "buffer around a reservation",
+			reserved: []models.TimeSlot{slot(at(11, 0), at(12, 0))},
+			buffer:   15 * time.Minute,
+			want:     []models.TimeSlot{slot(from, at(10, 45)), slot(at(12, 15), to)},
+		},
+		{
+			name:     "back to back reservations",
+			reserved: []models.TimeSlot{slot(at(10, 0), at(11, 0)), slot(at(11, 0), at(12, 0))},
+			want:     []models.TimeSlot{slot(from, at(10, 0)), slot(at(12, 0), to)},
+		},
+		{
+			name:     "gap smaller than the buffers",
+			reserved: []models.TimeSlot{slot(at(10, 0), at(11, 0)), slot(at(11, 20), at(12, 0))},
+			buffer:   15 * time.Minute,
+			want:     []models.TimeSlot{slot(from, at(9, 45)), slot(at(12, 15), to)},
+		},
+		{
+			name:     "reservation inside a longer one",
+			reserved: []models.TimeSlot{slot(at(10, 0), at(14, 0)), slot(at(11, 0), at(12, 0))},
+			want:     []models.TimeSlot{slot(from, at(10, 0)), slot(at(14, 0), to)},
+		},
+		{
+			name:     "reservations past the bounds",
+			reserved: []models.TimeSlot{slot(at(8, 0), at(10, 0)), slot(at(16, 0), at(18, 0))},
+			want:     []models.TimeSlot{slot(at(10, 0), at(16, 0))},
+		},
+		{
+			name:     "fully reserved",
+			reserved: []models.TimeSlot{slot(at(8, 0), at(18, 0))},
+			want:     []models.TimeSlot{},
+		},
+	}
+	for _, tt := range tests {
+		got := freeSlots(from, to, tt.reserved, tt.buffer)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: freeSlots = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckSlot(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	buffer := 15 * time.Minute
+
+	tests := []struct {
+		name string
+		rows [][]driver.Value
+		err  error
+	}{
+		{name: "free slot"},
+		{name: "overlapping reservation", rows: [][]driver.Value{{int64(7)}}, err: ErrSlotTaken},
+	}
+	for _, tt := range tests {
+		conn := &stubConn{rows: tt.rows}
+		db := sql.OpenDB(stubConnector{conn})
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatalf("%s: Begin returned error: %v", tt.name, err)
+		}
+
+		err = checkSlot(context.Background(), tx, 1, slot(start, end), buffer, 3)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: checkSlot = %v, want %v", tt.name, err, tt.err)
+		}
+		want := []driver.Value{int64(1), int64(3), end.Add(buffer), start.Add(-buffer)}
+		if !reflect.DeepEqual(conn.args, want) {
+			t.Errorf("%s: checkSlot queried %v, want %v", tt.name, conn.args, want)
+		}
+		tx.Rollback()
+		db.Close()
+	}
+}
+
+func TestReservationPlayers(t *testing.T) {
+	conn := &stubConn{
+		columns: []string{"ReservationID", "ID", "Name", "LV"},
+		rows: [][]driver.Value{
+			{int64(1), int64(7), "alice", int64(5)},
+			{int64(1), int64(8), "bob", int64(3)},
+			{int64(3), int64(7), "alice", int64(5)},
+		},
+	}
+	db := sql.OpenDB(stubConnector{conn})
+	defer db.Close()
+
+	players, err := reservationPlayers(context.Background(), db, 1, 2, 3)
+	if err != nil {
+		t.Fatalf("reservationPlayers returned error: %v", err)
+	}
+	want := map[int][]models.PlayerRank{
+		1: {{ID: 7, Name: "alice", LV: 5}, {ID: 8, Name: "bob", LV: 3}},
+		3: {{ID: 7, Name: "alice", LV: 5}},
+	}
+	if !reflect.DeepEqual(players, want) {
+		t.Errorf("reservationPlayers = %v, want %v", players, want)
+	}
+	if args := []driver.Value{int64(1), int64(2), int64(3)}; !reflect.DeepEqual(conn.args, args) {
+		t.Errorf("reservationPlayers queried %v, want %v", conn.args, args)
+	}
+}
+
+// stubConn answers every query with rows of the columns, ID by default, and
+// records the arguments of the last one.
+type stubConn struct {
+	columns []string
+	rows    [][]driver.Value
+	args    []driver.Value
+}
+
+func (c *stubConn) Prepare(query string) (driver.Stmt, error) { return &stubStmt{conn: c}, nil }
+func (c *stubConn) Close() error                              { return nil }
+func (c *stubConn) Begin() (driver.Tx, error)                 { return c, nil }
+func (c *stubConn) Commit() error                             { return nil }
+func (c *stubConn) Rollback() error                           { return nil }
+
+type stubStmt struct {
+	conn *stubConn
+}
+
+func (s *stubStmt) Close() error  { return nil }
+func (s *stubStmt) NumInput() int { return -1 }
+
+func (s *stubStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.args = args
+	return driver.RowsAffected(0), nil
+}
+
+func (s *stubStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.conn.args = args
+	columns := s.conn.columns
+	if columns == nil {
+		columns = []string{"ID"}
+	}
+	return &stubRows{columns: columns, rows: s.conn.rows}, nil
+}
+
+type stubRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *stubRows) Columns() []string { return r.columns }
+func (r *stubRows) Close() error      { return nil }
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+type stubConnector struct {
+	conn *stubConn
+}
+
+func (c stubConnector) Connect(context.Context) (driver.Conn, error) { return c.conn, nil }
+func (c stubConnector) Driver() driver.Driver                        { return nil }
@@ -0,0 +1,92 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
+	"golang.org/x/exp/slog"
+)
+
+// SweeperActor names the sweeper in the status history of the rooms.
+const SweeperActor = "reservations-sweeper"
+
+// sweepBatch bounds the reservations expired by one sweep, the next sweep takes the rest.
+const sweepBatch = 100
+
+// ExpireReservations marks the upcoming reservations nobody checked in by
+// noShowAfter past their start as no shows, and completes the checked in
+// reservations that ended by now, releasing their room in the same
+// transaction. It returns how many reservations it expired.
+func ExpireReservations(ctx context.Context, db *sql.DB, now time.Time, noShowAfter time.Duration) (int, error) {
+	ctx, span := tracing.Start(ctx, "databases.ExpireReservations")
+	defer span.End()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT R.ID
+		FROM Reservation R
+		WHERE (R.Status IN (?, ?) AND R.StartTime < ?) OR (R.Status = ? AND R.EndTime <= ?)
+		ORDER BY R.ID
+		LIMIT ?
+	`, models.ReservationPending, models.ReservationConfirmed, now.Add(-noShowAfter), models.ReservationCheckedIn, now, sweepBatch)
+	if err != nil {
+		return 0, fmt.Errorf("error querying database with ExpireReservations: %w", err)
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("error scanning row with ExpireReservations: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating over rows with ExpireReservations: %w", err)
+	}
+
+	expired := 0
+	for _, id := range ids {
+		// The reservations are checked again once locked, a check-in may have come first
+		status := models.ReservationStatus("")
+		err := changeReservation(ctx, db, id, func(tx *sql.Tx, r *models.ReservationRoom, room *models.Room) error {
+			switch {
+			case r.Status.Upcoming() && r.StartTime.Before(now.Add(-noShowAfter)):
+				status = models.ReservationNoShow
+				return setReservationStatus(ctx, tx, id, status)
+			case r.Status == models.ReservationCheckedIn && !r.EndTime.After(now):
+				status = models.ReservationCompleted
+				return completeReservation(ctx, tx, id, room, SweeperActor)
+			}
+			return nil
+		})
+		if err != nil {
+			return expired, err
+		}
+		if status != "" {
+			slog.InfoContext(ctx, "reservation expired", "reservation_id", id, "status", status)
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// SweepReservations expires the reservations every interval until ctx is done.
+func SweepReservations(ctx context.Context, db *sql.DB, interval, noShowAfter time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := ExpireReservations(ctx, db, time.Now().UTC(), noShowAfter); err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "error expiring the reservations", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
+)
+
+// MigrateRoomPlayers moves the players of the comma separated Room.PlayerIDs
+// to RoomPlayer. The entries that are not a player ID, or name a player that
+// does not exist, are reported and left in Room.PlayerIDs, the others are
+// removed from it, so the migration can be run again once they are fixed. The
+// players are moved whatever the capacity and level range of the room.
+func MigrateRoomPlayers(ctx context.Context, db *sql.DB) (*models.RoomPlayersMigration, error) {
+	ctx, span := tracing.Start(ctx, "databases.MigrateRoomPlayers")
+	defer span.End()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction with MigrateRoomPlayers: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT ID, PlayerIDs
+		FROM Room
+		WHERE PlayerIDs <> ''
+		ORDER BY ID
+		FOR UPDATE
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying database with MigrateRoomPlayers: %w", err)
+	}
+	playerIDs := map[int]string{}
+	var roomIDs []int
+	for rows.Next() {
+		var roomID int
+		var ids string
+		if err := rows.Scan(&roomID, &ids); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning row with MigrateRoomPlayers: %w", err)
+		}
+		roomIDs = append(roomIDs, roomID)
+		playerIDs[roomID] = ids
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows with MigrateRoomPlayers: %w", err)
+	}
+
+	migration := &models.RoomPlayersMigration{Unparsed: []models.UnparsedPlayerID{}}
+	for _, roomID := range roomIDs {
+		var left []string
+		for _, entry := range strings.Split(playerIDs[roomID], ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			playerID, err := strconv.Atoi(entry)
+			if err != nil || playerID <= 0 {
+				migration.Unparsed = append(migration.Unparsed, models.UnparsedPlayerID{RoomID: roomID, Entry: entry, Reason: "not a player id"})
+				left = append(left, entry)
+				continue
+			}
+			err = joinRoom(ctx, tx, &models.Room{ID: roomID}, playerID)
+			if errors.Is(err, ErrPlayerNotFound) {
+				migration.Unparsed = append(migration.Unparsed, models.UnparsedPlayerID{RoomID: roomID, Entry: entry, Reason: "player not found"})
+				left = append(left, entry)
+				continue
+			} else if err != nil && !errors.Is(err, ErrAlreadyInRoom) {
+				return nil, err
+			}
+			migration.Players++
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE Room SET PlayerIDs = ?
+			WHERE ID = ?
+		`, strings.Join(left, ", "), roomID)
+		if err != nil {
+			return nil, fmt.Errorf("error updating room with MigrateRoomPlayers: %w", err)
+		}
+		migration.Rooms++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction with MigrateRoomPlayers: %w", err)
+	}
+	return migration, nil
+}
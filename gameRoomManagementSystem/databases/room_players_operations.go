@@ -0,0 +1,168 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
+)
+
+func ListRoomPlayers(ctx context.Context, db *sql.DB, roomID int) ([]models.RoomPlayer, error) {
+	ctx, span := tracing.Start(ctx, "databases.ListRoomPlayers")
+	defer span.End()
+
+	var id int
+	err := db.QueryRowContext(ctx, `SELECT ID FROM Room WHERE ID = ?`, roomID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, ErrRoomNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("error querying database with ListRoomPlayers: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT
+		P.ID, P.Name, L.LV, RP.JoinedAt
+		FROM RoomPlayer RP
+		INNER JOIN Player P ON P.ID = RP.PlayerID
+		INNER JOIN Level L ON P.LevelID = L.ID
+		WHERE RP.RoomID = ? AND P.DeletedAt IS NULL
+		ORDER BY RP.JoinedAt, P.ID
+	`, roomID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying database with ListRoomPlayers: %w", err)
+	}
+	defer rows.Close()
+
+	players := []models.RoomPlayer{}
+	for rows.Next() {
+		var player models.RoomPlayer
+		err := rows.Scan(
+			&player.PlayerID,
+			&player.Name,
+			&player.LV,
+			&player.JoinedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row with ListRoomPlayers: %w", err)
+		}
+		players = append(players, player)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows with ListRoomPlayers: %w", err)
+	}
+	return players, nil
+}
+
+// AddRoomPlayer adds the player to the room, the room row is locked so two
+// joins cannot both take its last place.
+func AddRoomPlayer(ctx context.Context, db *sql.DB, roomID int, playerID int) error {
+	ctx, span := tracing.Start(ctx, "databases.AddRoomPlayer")
+	defer span.End()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction with AddRoomPlayer: %w", err)
+	}
+	defer tx.Rollback()
+
+	room, err := lockRoom(ctx, tx, roomID)
+	if err != nil {
+		return err
+	}
+
+	if err := joinRoom(ctx, tx, room, playerID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// joinRoom inserts the player of a room locked by tx, checking the level range
+// and the capacity of the room, the deleted players take no place in it.
+func joinRoom(ctx context.Context, tx *sql.Tx, room *models.Room, playerID int) error {
+	lv, err := playerLV(ctx, tx, playerID)
+	if err != nil {
+		return err
+	}
+
+	var joined, players int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(CASE WHEN RP.PlayerID = ? THEN 1 END), COUNT(*)
+		FROM RoomPlayer RP
+		INNER JOIN Player P ON P.ID = RP.PlayerID
+		WHERE RP.RoomID = ? AND P.DeletedAt IS NULL
+	`, playerID, room.ID).Scan(&joined, &players)
+	if err != nil {
+		return fmt.Errorf("error counting room players with joinRoom: %w", err)
+	}
+	if joined > 0 {
+		return ErrAlreadyInRoom
+	}
+	if err := checkLevel(room, playerID, lv); err != nil {
+		return err
+	}
+	if room.Capacity > 0 && players >= room.Capacity {
+		return ErrRoomFull
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO RoomPlayer (RoomID, PlayerID, JoinedAt)
+		VALUES (?, ?, ?)
+	`, room.ID, playerID, time.Now().UTC())
+	if isDuplicateEntry(err) {
+		return ErrAlreadyInRoom
+	} else if err != nil {
+		return fmt.Errorf("error inserting room player with joinRoom: %w", err)
+	}
+	return nil
+}
+
+// playerLV returns the LV of the player, ErrPlayerNotFound when it does not
+// exist or is deleted.
+func playerLV(ctx context.Context, tx *sql.Tx, playerID int) (int, error) {
+	var lv int
+	err := tx.QueryRowContext(ctx, `
+		SELECT L.LV
+		FROM Player P
+		INNER JOIN Level L ON P.LevelID = L.ID
+		WHERE P.ID = ? AND P.DeletedAt IS NULL
+	`, playerID).Scan(&lv)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("%w: %d", ErrPlayerNotFound, playerID)
+	} else if err != nil {
+		return 0, fmt.Errorf("error querying player with playerLV: %w", err)
+	}
+	return lv, nil
+}
+
+// checkLevel returns ErrLevelOutOfRange when lv is outside the level range of the room.
+func checkLevel(room *models.Room, playerID int, lv int) error {
+	if (room.MinLV != nil && lv < *room.MinLV) || (room.MaxLV != nil && lv > *room.MaxLV) {
+		return fmt.Errorf("%w: player %d has lv %d", ErrLevelOutOfRange, playerID, lv)
+	}
+	return nil
+}
+
+// RemoveRoomPlayer removes the player from the room, sql.ErrNoRows when the player is not in it.
+func RemoveRoomPlayer(ctx context.Context, db *sql.DB, roomID int, playerID int) error {
+	ctx, span := tracing.Start(ctx, "databases.RemoveRoomPlayer")
+	defer span.End()
+
+	result, err := db.ExecContext(ctx, `
+		DELETE FROM RoomPlayer
+		WHERE RoomID = ? AND PlayerID = ?
+	`, roomID, playerID)
+	if err != nil {
+		return fmt.Errorf("error querying database with RemoveRoomPlayer: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
+)
+
+// TransitionRoom applies event to the status of the room and records the
+// transition in its history, ErrIllegalTransition when the status does not
+// allow the event. The room row is locked so concurrent events are applied
+// one after the other.
+func TransitionRoom(ctx context.Context, db *sql.DB, roomID int, event models.RoomEvent, actor string, reason string) (*models.RoomTransition, error) {
+	ctx, span := tracing.Start(ctx, "databases.TransitionRoom")
+	defer span.End()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction with TransitionRoom: %w", err)
+	}
+	defer tx.Rollback()
+
+	transition, err := transitionRoom(ctx, tx, roomID, event, actor, reason)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction with TransitionRoom: %w", err)
+	}
+	return transition, nil
+}
+
+// transitionRoom applies event to the room within tx.
+func transitionRoom(ctx context.Context, tx *sql.Tx, roomID int, event models.RoomEvent, actor string, reason string) (*models.RoomTransition, error) {
+	var from models.Status
+	err := tx.QueryRowContext(ctx, `SELECT Status FROM Room WHERE ID = ? FOR UPDATE`, roomID).Scan(&from)
+	if err == sql.ErrNoRows {
+		return nil, ErrRoomNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("error locking room with transitionRoom: %w", err)
+	}
+
+	to, ok := from.Next(event)
+	if !ok {
+		return nil, fmt.Errorf("%w: cannot %s a room that is %s", ErrIllegalTransition, event, from)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE Room SET Status = ? WHERE ID = ?`, to, roomID)
+	if err != nil {
+		return nil, fmt.Errorf("error updating room status with transitionRoom: %w", err)
+	}
+
+	transition := models.RoomTransition{
+		RoomID:    roomID,
+		Event:     event,
+		From:      from,
+		To:        to,
+		Actor:     actor,
+		Reason:    reason,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO RoomStatusHistory (RoomID, Event, FromStatus, ToStatus, Actor, Reason, CreatedAt)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, transition.RoomID, transition.Event, transition.From, transition.To, transition.Actor, transition.Reason, transition.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error inserting room status history with transitionRoom: %w", err)
+	}
+	transition.ID, _ = result.LastInsertId()
+	return &transition, nil
+}
+
+// ListRoomTransitions returns the latest transitions of the room first, at most limit of them.
+func ListRoomTransitions(ctx context.Context, db *sql.DB, roomID int, limit int) ([]models.RoomTransition, error) {
+	ctx, span := tracing.Start(ctx, "databases.ListRoomTransitions")
+	defer span.End()
+
+	var id int
+	err := db.QueryRowContext(ctx, `SELECT ID FROM Room WHERE ID = ?`, roomID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, ErrRoomNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("error querying database with ListRoomTransitions: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT
+		ID, RoomID, Event, FromStatus, ToStatus, Actor, Reason, CreatedAt
+		FROM RoomStatusHistory
+		WHERE RoomID = ?
+		ORDER BY ID DESC
+		LIMIT ?
+	`, roomID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error querying database with ListRoomTransitions: %w", err)
+	}
+	defer rows.Close()
+
+	transitions := []models.RoomTransition{}
+	for rows.Next() {
+		var transition models.RoomTransition
+		err := rows.Scan(
+			&transition.ID,
+			&transition.RoomID,
+			&transition.Event,
+			&transition.From,
+			&transition.To,
+			&transition.Actor,
+			&transition.Reason,
+			&transition.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row with ListRoomTransitions: %w", err)
+		}
+		transitions = append(transitions, transition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows with ListRoomTransitions: %w", err)
+	}
+	return transitions, nil
+}
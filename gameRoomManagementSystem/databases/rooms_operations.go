@@ -1,20 +1,58 @@
 package databases
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
 
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
 )
 
-func ListRooms(db *sql.DB) ([]models.Room, error) {
-	var query string = `
-		SELECT 
-		ID, Name, Status 
-		FROM Room
-	`
-	rows, err := db.Query(query)
+// roomColumns are the columns of models.Room read by scanRoom, with the number
+// of players in the room who are not deleted.
+const roomColumns = `
+	ID, Name, Status, Capacity, MinPlayers, MinLV, MaxLV, Private,
+	(SELECT COUNT(*) FROM RoomPlayer RP INNER JOIN Player P ON P.ID = RP.PlayerID WHERE RP.RoomID = Room.ID AND P.DeletedAt IS NULL)
+`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRoom(row scanner, room *models.Room) error {
+	var minLV, maxLV sql.NullInt64
+	err := row.Scan(
+		&room.ID,
+		&room.Name,
+		&room.Status,
+		&room.Capacity,
+		&room.MinPlayers,
+		&minLV,
+		&maxLV,
+		&room.Private,
+		&room.Players,
+	)
+	room.MinLV = nullInt(minLV)
+	room.MaxLV = nullInt(maxLV)
+	return err
+}
+
+func nullInt(n sql.NullInt64) *int {
+	if !n.Valid {
+		return nil
+	}
+	v := int(n.Int64)
+	return &v
+}
+
+func ListRooms(ctx context.Context, db *sql.DB) ([]models.Room, error) {
+	ctx, span := tracing.Start(ctx, "databases.ListRooms")
+	defer span.End()
+
+	var query string = `SELECT ` + roomColumns + ` FROM Room`
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("error querying database with ListRooms: %w", err)
 	}
@@ -23,12 +61,7 @@ func ListRooms(db *sql.DB) ([]models.Room, error) {
 	var rooms []models.Room
 	for rows.Next() {
 		var room models.Room
-		err := rows.Scan(
-			&room.ID,
-			&room.Name,
-			&room.Status,
-		)
-		if err != nil {
+		if err := scanRoom(rows, &room); err != nil {
 			return nil, fmt.Errorf("error scanning row with ListRooms: %w", err)
 		}
 		rooms = append(rooms, room)
@@ -36,18 +69,12 @@ func ListRooms(db *sql.DB) ([]models.Room, error) {
 	return rooms, nil
 }
 
-func ShowRoom(db *sql.DB, id int) (*models.Room, error) {
+func ShowRoom(ctx context.Context, db *sql.DB, id int) (*models.Room, error) {
+	ctx, span := tracing.Start(ctx, "databases.ShowRoom")
+	defer span.End()
+
 	var room models.Room
-	err := db.QueryRow(`
-		SELECT 
-		ID, Name, Status
-		FROM Room 
-		WHERE ID = ?
-	`, id).Scan(
-		&room.ID,
-		&room.Name,
-		&room.Status,
-	)
+	err := scanRoom(db.QueryRowContext(ctx, `SELECT `+roomColumns+` FROM Room WHERE ID = ?`, id), &room)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("error querying database with Show Room: %w", err)
 	} else if err != nil {
@@ -56,11 +83,48 @@ func ShowRoom(db *sql.DB, id int) (*models.Room, error) {
 	return &room, nil
 }
 
-func AddRoom(db *sql.DB, name string, description string) (int, error) {
-	result, err := db.Exec(`
-		INSERT INTO Room (Name, Status, Description, PlayerIDs) 
-		VALUES (?, 0, ?, "")
-	`, name, description)
+// ShowRoomSecret returns the invite code and the password hash of a private room, empty when it has none.
+func ShowRoomSecret(ctx context.Context, db *sql.DB, id int) (bool, string, string, error) {
+	ctx, span := tracing.Start(ctx, "databases.ShowRoomSecret")
+	defer span.End()
+
+	var private bool
+	var inviteCode, passwordHash sql.NullString
+	err := db.QueryRowContext(ctx, `
+		SELECT Private, InviteCode, PasswordHash
+		FROM Room
+		WHERE ID = ?
+	`, id).Scan(&private, &inviteCode, &passwordHash)
+	if err == sql.ErrNoRows {
+		return false, "", "", ErrRoomNotFound
+	} else if err != nil {
+		return false, "", "", fmt.Errorf("error querying database with ShowRoomSecret: %w", err)
+	}
+	return private, inviteCode.String, passwordHash.String, nil
+}
+
+// lockRoom locks the room row until tx ends, so the joins of the room are
+// checked against its rules one after the other.
+func lockRoom(ctx context.Context, tx *sql.Tx, id int) (*models.Room, error) {
+	var room models.Room
+	err := scanRoom(tx.QueryRowContext(ctx, `SELECT `+roomColumns+` FROM Room WHERE ID = ? FOR UPDATE`, id), &room)
+	if err == sql.ErrNoRows {
+		return nil, ErrRoomNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("error locking room: %w", err)
+	}
+	return &room, nil
+}
+
+// AddRoom inserts the room with the hash of its password and its invite code, empty when it has none.
+func AddRoom(ctx context.Context, db *sql.DB, room models.Room, passwordHash string, inviteCode string) (int, error) {
+	ctx, span := tracing.Start(ctx, "databases.AddRoom")
+	defer span.End()
+
+	result, err := db.ExecContext(ctx, `
+		INSERT INTO Room (Name, Status, Description, PlayerIDs, Capacity, MinPlayers, MinLV, MaxLV, Private, InviteCode, PasswordHash) 
+		VALUES (?, 0, ?, "", ?, ?, ?, ?, ?, NULLIF(?, ''), NULLIF(?, ''))
+	`, room.Name, room.Description, room.Capacity, room.MinPlayers, room.MinLV, room.MaxLV, room.Private, inviteCode, passwordHash)
 	if err != nil {
 		return 0, fmt.Errorf("error querying database with AddRoom: %w", err)
 	}
@@ -68,58 +132,101 @@ func AddRoom(db *sql.DB, name string, description string) (int, error) {
 	return int(id), err
 }
 
-func UpdateRoomData(db *sql.DB, room models.Room) error {
+// UpdateRoomData applies the update to the room, the fields left out keep
+// their value. The room is locked and check validates its rules with the
+// update applied before they are written. The password is only changed when
+// passwordHash is set, and a private room without an invite code gets
+// inviteCode. The status is left to TransitionRoom.
+func UpdateRoomData(ctx context.Context, db *sql.DB, update models.RoomUpdate, passwordHash string, inviteCode string, check func(models.Room) error) error {
+	ctx, span := tracing.Start(ctx, "databases.UpdateRoomData")
+	defer span.End()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction with UpdateRoomData: %w", err)
+	}
+	defer tx.Rollback()
+
+	room, err := lockRoom(ctx, tx, update.ID)
+	if err != nil {
+		return err
+	}
+	update.Apply(room)
+	if err := check(*room); err != nil {
+		return err
+	}
+
 	query := "UPDATE Room SET"
 	args := []interface{}{}
 	updates := []string{}
 
-	if room.Name != "" {
+	if update.Name != "" {
 		updates = append(updates, "Name = ?")
-		args = append(args, room.Name)
+		args = append(args, update.Name)
 	}
 
-	if room.Status != 0 {
-		updates = append(updates, "Status = ?")
-		args = append(args, room.Status)
+	if update.Description != "" {
+		updates = append(updates, "Description = ?")
+		args = append(args, update.Description)
 	}
 
-	if room.Description != "" {
-		updates = append(updates, "Description = ?")
-		args = append(args, room.Description)
+	if update.Capacity != nil {
+		updates = append(updates, "Capacity = ?")
+		args = append(args, *update.Capacity)
+	}
+
+	if update.MinPlayers != nil {
+		updates = append(updates, "MinPlayers = ?")
+		args = append(args, *update.MinPlayers)
+	}
+
+	if update.MinLV != nil {
+		updates = append(updates, "MinLV = ?")
+		args = append(args, *update.MinLV)
 	}
 
-	//need to think about it to do it
-	if room.PlayerIDs != "" {
-		updates = append(updates, "PlayerIDs = ?")
-		args = append(args, room.PlayerIDs)
+	if update.MaxLV != nil {
+		updates = append(updates, "MaxLV = ?")
+		args = append(args, *update.MaxLV)
+	}
+
+	if update.Private != nil {
+		updates = append(updates, "Private = ?")
+		args = append(args, *update.Private)
+	}
+
+	if passwordHash != "" {
+		updates = append(updates, "PasswordHash = ?")
+		args = append(args, passwordHash)
+	}
+
+	if room.Private && inviteCode != "" {
+		updates = append(updates, "InviteCode = COALESCE(InviteCode, ?)")
+		args = append(args, inviteCode)
 	}
 
 	if len(updates) == 0 {
-		return fmt.Errorf("no fields update for Room with id: %d", room.ID)
+		return nil
 	}
 
 	query += " " + strings.Join(updates, ", ")
 	query += " WHERE id = ?"
 	args = append(args, room.ID)
 
-	result, err := db.Exec(query, args...)
-	if err != nil {
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
 		return fmt.Errorf("error querying database with UpdateRoomData: %w", err)
 	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("error updating room: %w", err)
-	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("no rows were updated, room with id %d may not exist", room.ID)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction with UpdateRoomData: %w", err)
 	}
-
 	return nil
 }
 
-func DeleteRoom(db *sql.DB, id int) error {
-	result, err := db.Exec(`
+func DeleteRoom(ctx context.Context, db *sql.DB, id int) error {
+	ctx, span := tracing.Start(ctx, "databases.DeleteRoom")
+	defer span.End()
+
+	result, err := db.ExecContext(ctx, `
 		DELETE FROM Room 
 		WHERE ID = ?
 	`, id)
@@ -137,25 +244,21 @@ func DeleteRoom(db *sql.DB, id int) error {
 	return nil
 }
 
-func SearchPlayerInRoom(db *sql.DB, playerIDs []int) ([]models.PlayerRank, error) {
-	// Create a string of placeholders for the IN clause
-	placeholders := make([]string, len(playerIDs))
-	args := make([]interface{}, len(playerIDs))
-	for i, id := range playerIDs {
-		placeholders[i] = "?"
-		args[i] = id
-	}
-
-	var query string = fmt.Sprintf(`
-        SELECT 
-		P.ID, P.Name, L.LV 
-        FROM Player P
-        INNER JOIN Level L ON P.LevelID = L.ID
-        WHERE P.ID IN (%s)
-        ORDER BY P.ID
-    `, strings.Join(placeholders, ","))
-
-	rows, err := db.Query(query, args...)
+// SearchPlayerInRoom returns the players in the room with their level, the
+// deleted players are left out.
+func SearchPlayerInRoom(ctx context.Context, db *sql.DB, roomID int) ([]models.PlayerRank, error) {
+	ctx, span := tracing.Start(ctx, "databases.SearchPlayerInRoom")
+	defer span.End()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT
+		P.ID, P.Name, L.LV
+		FROM RoomPlayer RP
+		INNER JOIN Player P ON P.ID = RP.PlayerID
+		INNER JOIN Level L ON P.LevelID = L.ID
+		WHERE RP.RoomID = ? AND P.DeletedAt IS NULL
+		ORDER BY P.ID
+	`, roomID)
 	if err != nil {
 		return nil, fmt.Errorf("error querying database with searchPlayerInRoom: %w", err)
 	}
@@ -179,10 +282,5 @@ func SearchPlayerInRoom(db *sql.DB, playerIDs []int) ([]models.PlayerRank, error
 		return nil, fmt.Errorf("error iterating over rows with searchPlayerInRoom: %w", err)
 	}
 
-	// Check if all requested players were found
-	if len(playerRanks) != len(playerIDs) {
-		return playerRanks, fmt.Errorf("some players were not found")
-	}
-
 	return playerRanks, nil
 }
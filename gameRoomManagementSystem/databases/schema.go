@@ -0,0 +1,5 @@
+package databases
+
+// SchemaVersion is the version of the SchemaVersion table the queries of this
+// service are written for, /readyz fails until the database reaches it.
+const SchemaVersion = 7
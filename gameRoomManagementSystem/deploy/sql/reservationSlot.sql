@@ -0,0 +1,70 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- MySQL Script generated by MySQL Workbench
+-- Sat Jul  27 16:09:21 2024
+-- Model: New Model    Version: 1.0
+-- MySQL Workbench Forward Engineering;
+
+SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0;
+SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0;
+SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_ZERO_IN_DATE,NO_ZERO_DATE,ERROR_FOR_DIVISION_BY_ZERO,NO_ENGINE_SUBSTITUTION';
+
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+CREATE SCHEMA IF NOT EXISTS `SpinnrTechnology` DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci ;
+USE `SpinnrTechnology` ;
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`Reservation`
+-- Time slot of the reservations, replacing their Date. The reservations made
+-- before it take the whole day of their Date.
+-- -----------------------------------------------------
+ALTER TABLE `SpinnrTechnology`.`Reservation`
+    ADD COLUMN `StartTime` DATETIME NULL DEFAULT NULL,
+    ADD COLUMN `EndTime` DATETIME NULL DEFAULT NULL;
+
+UPDATE `SpinnrTechnology`.`Reservation`
+SET `StartTime` = `Date`, `EndTime` = `Date` + INTERVAL 1 DAY;
+
+ALTER TABLE `SpinnrTechnology`.`Reservation`
+    MODIFY COLUMN `StartTime` DATETIME NOT NULL,
+    MODIFY COLUMN `EndTime` DATETIME NOT NULL,
+    ADD INDEX `IX_Reservation_RoomID_StartTime` (`RoomID`, `StartTime`),
+    DROP COLUMN `Date`;
+
+INSERT IGNORE INTO `SpinnrTechnology`.`SchemaVersion` (`Version`, `AppliedAt`) VALUES (5, UTC_TIMESTAMP());
+
+
+SET SQL_MODE=@OLD_SQL_MODE;
+SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;
+SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS;
+
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DELETE FROM `SpinnrTechnology`.`SchemaVersion` WHERE `Version` = 5;
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`Reservation`
+-- -----------------------------------------------------
+ALTER TABLE `SpinnrTechnology`.`Reservation`
+    ADD COLUMN `Date` DATETIME NULL DEFAULT NULL;
+
+UPDATE `SpinnrTechnology`.`Reservation`
+SET `Date` = `StartTime`;
+
+ALTER TABLE `SpinnrTechnology`.`Reservation`
+    MODIFY COLUMN `Date` DATETIME NOT NULL,
+    -- The foreign key of RoomID may use the index of the time slots
+    ADD INDEX `IX_Reservation_RoomID` (`RoomID`);
+
+ALTER TABLE `SpinnrTechnology`.`Reservation`
+    DROP INDEX `IX_Reservation_RoomID_StartTime`,
+    DROP COLUMN `StartTime`,
+    DROP COLUMN `EndTime`;
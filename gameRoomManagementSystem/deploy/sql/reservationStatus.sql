@@ -0,0 +1,56 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- MySQL Script generated by MySQL Workbench
+-- Sat Jul  27 16:09:21 2024
+-- Model: New Model    Version: 1.0
+-- MySQL Workbench Forward Engineering;
+
+SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0;
+SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0;
+SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_ZERO_IN_DATE,NO_ZERO_DATE,ERROR_FOR_DIVISION_BY_ZERO,NO_ENGINE_SUBSTITUTION';
+
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+CREATE SCHEMA IF NOT EXISTS `SpinnrTechnology` DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci ;
+USE `SpinnrTechnology` ;
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`Reservation`
+-- Status of the reservations and the player who made them. The reservations
+-- made before it are confirmed, or completed when they are over.
+-- -----------------------------------------------------
+ALTER TABLE `SpinnrTechnology`.`Reservation`
+    ADD COLUMN `Status` VARCHAR(16) NOT NULL DEFAULT 'pending',
+    ADD COLUMN `PlayerID` INT NULL DEFAULT NULL,
+    ADD COLUMN `CheckedInAt` DATETIME NULL DEFAULT NULL,
+    ADD INDEX `IX_Reservation_Status_StartTime` (`Status`, `StartTime`);
+
+UPDATE `SpinnrTechnology`.`Reservation`
+SET `Status` = IF(`EndTime` < UTC_TIMESTAMP(), 'completed', 'confirmed');
+
+INSERT IGNORE INTO `SpinnrTechnology`.`SchemaVersion` (`Version`, `AppliedAt`) VALUES (6, UTC_TIMESTAMP());
+
+
+SET SQL_MODE=@OLD_SQL_MODE;
+SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;
+SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS;
+
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DELETE FROM `SpinnrTechnology`.`SchemaVersion` WHERE `Version` = 6;
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`Reservation`
+-- -----------------------------------------------------
+ALTER TABLE `SpinnrTechnology`.`Reservation`
+    DROP INDEX `IX_Reservation_Status_StartTime`,
+    DROP COLUMN `Status`,
+    DROP COLUMN `PlayerID`,
+    DROP COLUMN `CheckedInAt`;
@@ -28,7 +28,17 @@ CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`Room` (
     `Name` VARCHAR(255) NOT NULL,
     `Status` INT NOT NULL,
     `Description` TEXT NOT NULL,
-    `PlayerIDs` TEXT NOT NULL)
+    -- Only read by the migration to RoomPlayer, it keeps the entries that could not be moved
+    `PlayerIDs` TEXT NOT NULL,
+    -- Join rules, a Capacity of 0 has no limit and MinLV and MaxLV are checked when set
+    `Capacity` INT NOT NULL DEFAULT 0,
+    `MinPlayers` INT NOT NULL DEFAULT 0,
+    `MinLV` INT NULL DEFAULT NULL,
+    `MaxLV` INT NULL DEFAULT NULL,
+    -- Private rooms are joined with the invite code or the password
+    `Private` BOOLEAN NOT NULL DEFAULT FALSE,
+    `InviteCode` VARCHAR(32) NULL DEFAULT NULL,
+    `PasswordHash` VARCHAR(255) NULL DEFAULT NULL)
 ENGINE = InnoDB
 DEFAULT CHARACTER SET = utf8mb4
 COLLATE = utf8mb4_0900_ai_ci;
@@ -0,0 +1,55 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- MySQL Script generated by MySQL Workbench
+-- Sat Jul  27 16:09:21 2024
+-- Model: New Model    Version: 1.0
+-- MySQL Workbench Forward Engineering;
+
+SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0;
+SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0;
+SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_ZERO_IN_DATE,NO_ZERO_DATE,ERROR_FOR_DIVISION_BY_ZERO,NO_ENGINE_SUBSTITUTION';
+
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+CREATE SCHEMA IF NOT EXISTS `SpinnrTechnology` DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci ;
+USE `SpinnrTechnology` ;
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`RoomPlayer`
+-- Players in the rooms, replacing the comma separated Room.PlayerIDs. Existing
+-- PlayerIDs are moved here by gameRoomManagementSystem -migrate-room-players.
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`RoomPlayer` (
+    `RoomID` INT NOT NULL,
+    `PlayerID` INT NOT NULL,
+    `JoinedAt` DATETIME NOT NULL,
+    PRIMARY KEY (`RoomID`, `PlayerID`),
+    INDEX `IX_RoomPlayer_PlayerID` (`PlayerID`),
+    FOREIGN KEY (`RoomID`) REFERENCES `Room`(`ID`) ON DELETE CASCADE,
+    FOREIGN KEY (`PlayerID`) REFERENCES `Player`(`ID`) ON DELETE CASCADE)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+INSERT IGNORE INTO `SpinnrTechnology`.`SchemaVersion` (`Version`, `AppliedAt`) VALUES (2, UTC_TIMESTAMP());
+
+
+SET SQL_MODE=@OLD_SQL_MODE;
+SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;
+SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS;
+
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DELETE FROM `SpinnrTechnology`.`SchemaVersion` WHERE `Version` = 2;
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`RoomPlayer`
+-- -----------------------------------------------------
+DROP TABLE IF EXISTS `SpinnrTechnology`.`RoomPlayer` ;
@@ -0,0 +1,58 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- MySQL Script generated by MySQL Workbench
+-- Sat Jul  27 16:09:21 2024
+-- Model: New Model    Version: 1.0
+-- MySQL Workbench Forward Engineering;
+
+SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0;
+SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0;
+SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_ZERO_IN_DATE,NO_ZERO_DATE,ERROR_FOR_DIVISION_BY_ZERO,NO_ENGINE_SUBSTITUTION';
+
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+CREATE SCHEMA IF NOT EXISTS `SpinnrTechnology` DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci ;
+USE `SpinnrTechnology` ;
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`Room`
+-- Join rules of the rooms, added to the rooms created before them.
+-- -----------------------------------------------------
+ALTER TABLE `SpinnrTechnology`.`Room`
+    ADD COLUMN `Capacity` INT NOT NULL DEFAULT 0,
+    ADD COLUMN `MinPlayers` INT NOT NULL DEFAULT 0,
+    ADD COLUMN `MinLV` INT NULL DEFAULT NULL,
+    ADD COLUMN `MaxLV` INT NULL DEFAULT NULL,
+    ADD COLUMN `Private` BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN `InviteCode` VARCHAR(32) NULL DEFAULT NULL,
+    ADD COLUMN `PasswordHash` VARCHAR(255) NULL DEFAULT NULL;
+
+INSERT IGNORE INTO `SpinnrTechnology`.`SchemaVersion` (`Version`, `AppliedAt`) VALUES (3, UTC_TIMESTAMP());
+
+
+SET SQL_MODE=@OLD_SQL_MODE;
+SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;
+SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS;
+
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DELETE FROM `SpinnrTechnology`.`SchemaVersion` WHERE `Version` = 3;
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`Room`
+-- -----------------------------------------------------
+ALTER TABLE `SpinnrTechnology`.`Room`
+    DROP COLUMN `Capacity`,
+    DROP COLUMN `MinPlayers`,
+    DROP COLUMN `MinLV`,
+    DROP COLUMN `MaxLV`,
+    DROP COLUMN `Private`,
+    DROP COLUMN `InviteCode`,
+    DROP COLUMN `PasswordHash`;
@@ -0,0 +1,58 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- MySQL Script generated by MySQL Workbench
+-- Sat Jul  27 16:09:21 2024
+-- Model: New Model    Version: 1.0
+-- MySQL Workbench Forward Engineering;
+
+SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0;
+SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0;
+SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_ZERO_IN_DATE,NO_ZERO_DATE,ERROR_FOR_DIVISION_BY_ZERO,NO_ENGINE_SUBSTITUTION';
+
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+CREATE SCHEMA IF NOT EXISTS `SpinnrTechnology` DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci ;
+USE `SpinnrTechnology` ;
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`RoomStatusHistory`
+-- Transitions of the status of the rooms, made with POST /rooms/:id/transitions
+-- of gameRoomManagementSystem.
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`RoomStatusHistory` (
+    `ID` BIGINT AUTO_INCREMENT PRIMARY KEY,
+    `RoomID` INT NOT NULL,
+    `Event` VARCHAR(32) NOT NULL,
+    `FromStatus` INT NOT NULL,
+    `ToStatus` INT NOT NULL,
+    `Actor` VARCHAR(255) NOT NULL,
+    `Reason` VARCHAR(255) NOT NULL,
+    `CreatedAt` DATETIME NOT NULL,
+    INDEX `IX_RoomStatusHistory_RoomID` (`RoomID`, `ID`),
+    FOREIGN KEY (`RoomID`) REFERENCES `Room`(`ID`) ON DELETE CASCADE)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+INSERT IGNORE INTO `SpinnrTechnology`.`SchemaVersion` (`Version`, `AppliedAt`) VALUES (4, UTC_TIMESTAMP());
+
+
+SET SQL_MODE=@OLD_SQL_MODE;
+SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;
+SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS;
+
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+DELETE FROM `SpinnrTechnology`.`SchemaVersion` WHERE `Version` = 4;
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`RoomStatusHistory`
+-- -----------------------------------------------------
+DROP TABLE IF EXISTS `SpinnrTechnology`.`RoomStatusHistory` ;
@@ -9,6 +9,7 @@ const docTemplate = `{
     "info": {
         "description": "{{escape .Description}}",
         "title": "{{.Title}}",
+        "termsOfService": "http://swagger.io/terms/",
         "contact": {
             "name": "Steven Poon",
             "url": "https://github.com/RYANCOAL9999",
@@ -23,6 +24,52 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/healthz": {
+            "get": {
+                "description": "Reports that the process is alive, it does not check any dependency.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness",
+                "responses": {
+                    "200": {
+                        "description": "The process is alive",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Runs the readiness checks of the service, such as the database, the schema version and the services it depends on, with the status and latency of each.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness",
+                "responses": {
+                    "200": {
+                        "description": "Every check passed",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "A check failed or the service is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
         "/reservations": {
             "get": {
                 "description": "Get a list of reservations based on optional filters such as room ID, start date, end date, and limit. Returns reservations that match the criteria.",
@@ -87,7 +134,15 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Creates a new reservation for a specified room if the room is available. The request body must include the room ID, date of reservation, and player IDs. If successful, returns the ID of the created reservation.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Creates a new pending reservation of a time slot of a room. The request body must include the room ID, the start and end times of the reservation in RFC 3339, and the IDs of the players, who are checked as they join the room, at most its capacity. Players reserve for themselves, the other players need the rooms:players permission. The slot must be in the future and must not overlap another reservation of the room, nor the buffer kept between two reservations, see GET /rooms/{id}/availability. Closed rooms cannot be reserved and private rooms need their invite code or password. If successful, returns the ID of the created reservation.",
                 "consumes": [
                     "application/json"
                 ],
@@ -117,7 +172,37 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad request due to invalid input or date format",
+                        "description": "Bad request due to invalid input or time slot",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing permission rooms:players, invalid invite code or password, or LV of a player outside the range of the room",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Room or player not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Time slot already reserved, room closed or more players than its capacity",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -131,9 +216,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms": {
+        "/reservations/{id}": {
             "get": {
-                "description": "Get a list of all rooms available in the database along with their details such as name, status, description, and player IDs.",
+                "description": "Fetch the reservation with its time slot, status, the player who made it and its players.",
                 "consumes": [
                     "application/json"
                 ],
@@ -141,17 +226,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "reservations"
+                ],
+                "summary": "Retrieve a reservation by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Retrieve all rooms",
                 "responses": {
                     "200": {
-                        "description": "List of rooms",
+                        "description": "Details of the reservation",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Room"
-                            }
+                            "$ref": "#/definitions/models.ReservationRoom"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID supplied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
@@ -163,7 +266,15 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Update the details of an existing room in the database. The request body should include the room's ID, name, status, description, and player IDs. The ID is used to identify the room to be updated.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Moves a pending or confirmed reservation to another time slot of its room, in the future and free of the other reservations and the buffer kept between them. Players reschedule the reservations they made, the others need the reservations:manage permission.",
                 "consumes": [
                     "application/json"
                 ],
@@ -171,29 +282,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "reservations"
                 ],
-                "summary": "Update a room",
+                "summary": "Reschedule a reservation",
                 "parameters": [
                     {
-                        "description": "Room details to be updated",
-                        "name": "room",
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New time slot",
+                        "name": "slot",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Room"
+                            "$ref": "#/definitions/models.RescheduleReservation"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Update successful",
+                        "description": "Reservation rescheduled",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad request due to invalid input",
+                        "description": "Bad request due to invalid input or time slot",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Reservation of another player without permission reservations:manage",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Time slot already reserved, or reservation no longer pending or confirmed",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -206,8 +348,16 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
-                "description": "Add a new room to the database with the provided name, description, and status. PlayerIDs are optional and can be set later.",
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Cancels a pending or confirmed reservation, its time slot is free again. Players cancel the reservations they made, the others need the reservations:manage permission.",
                 "consumes": [
                     "application/json"
                 ],
@@ -215,29 +365,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "reservations"
                 ],
-                "summary": "Create a new room",
+                "summary": "Cancel a reservation",
                 "parameters": [
                     {
-                        "description": "Room details to be created",
-                        "name": "room",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Room"
-                        }
+                        "type": "integer",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "ID of the created room",
+                    "200": {
+                        "description": "Reservation cancelled",
                         "schema": {
-                            "$ref": "#/definitions/models.CreateResponse"
+                            "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad request due to invalid input",
+                        "description": "Invalid ID supplied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Reservation of another player without permission reservations:manage",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Reservation no longer pending or confirmed",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -251,9 +423,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/rooms/{id}": {
-            "get": {
-                "description": "Fetch details of a specific room from the database identified by its ID. Returns room details including name, status, description, and player IDs.",
+        "/reservations/{id}/check-in": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Checks in a pending or confirmed reservation when its players arrive and occupies its room, recorded in the status history of the room. The check-in opens the reservation buffer before the start of the reservation and closes when the reservation becomes a no show. The room must be available, a reservation of the room that ended is completed first. Players check in the reservations they made, the others need the reservations:manage permission.",
                 "consumes": [
                     "application/json"
                 ],
@@ -261,13 +441,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "reservations"
                 ],
-                "summary": "Retrieve a room by ID",
+                "summary": "Check in a reservation",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Room ID",
+                        "description": "Reservation ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -275,12 +455,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Details of the room",
+                        "description": "Reservation checked in",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Room"
-                            }
+                            "$ref": "#/definitions/models.SuccessResponse"
                         }
                     },
                     "400": {
@@ -289,6 +466,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Reservation of another player without permission reservations:manage",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Outside the check-in window, reservation no longer pending or confirmed, or room not available",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -296,9 +497,19 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Remove a specific room from the database using its ID. If the room exists, it will be deleted.",
+            }
+        },
+        "/reservations/{id}/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Confirms a pending reservation.",
                 "consumes": [
                     "application/json"
                 ],
@@ -306,13 +517,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rooms"
+                    "reservations"
                 ],
-                "summary": "Delete a room",
+                "summary": "Confirm a reservation",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Room ID",
+                        "description": "Reservation ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -320,7 +531,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "Delete successful",
+                        "description": "Reservation confirmed",
                         "schema": {
                             "$ref": "#/definitions/models.SuccessResponse"
                         }
@@ -331,6 +542,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing permission reservations:manage",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Reservation not pending",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -339,65 +574,899 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.CreateResponse": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "integer"
-                }
-            }
-        },
-        "models.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
-                }
-            }
         },
-        "models.PlayerRank": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "integer"
-                },
-                "lv": {
-                    "type": "integer"
-                },
-                "name": {
-                    "type": "string"
+        "/rooms": {
+            "get": {
+                "description": "Get a list of all rooms available in the database along with their details such as name and status.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Retrieve all rooms",
+                "responses": {
+                    "200": {
+                        "description": "List of rooms",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Room"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Update the details of an existing room in the database. The request body should include the room's ID and the fields to change, the name, description and join rules left out keep their value. A private room keeps its invite code, or gets one, and its password unless a new one is given. The ID is used to identify the room to be updated. The status is changed with POST /rooms/{id}/transitions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Update a room",
+                "parameters": [
+                    {
+                        "description": "Room details to be updated",
+                        "name": "room",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RoomUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Update successful",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request due to invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing permission rooms:update",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Add a new room to the database with the provided name, description and join rules: the capacity, the fewest players of a reservation, the LV range of the players and whether the room is private. Private rooms get an invite code, returned once, and can have a password. Players join it with POST /rooms/{id}/players.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Create a new room",
+                "parameters": [
+                    {
+                        "description": "Room details to be created",
+                        "name": "room",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Room"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ID of the created room and the invite code of a private room",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateRoomResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request due to invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing permission rooms:create",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}": {
+            "get": {
+                "description": "Fetch details of a specific room from the database identified by its ID. Returns room details including name, status, join rules and number of players, and the invite code of a private room to the players allowed to update rooms.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Retrieve a room by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Details of the room",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Room"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID supplied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Remove a specific room from the database using its ID. If the room exists, it will be deleted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Delete a room",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Delete successful",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID supplied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing permission rooms:delete",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/availability": {
+            "get": {
+                "description": "Get the free time slots of the room between from and to, in RFC 3339, a reservation fits in any part of them. The slots are cut short by the reservations of the room and the buffer kept between two reservations, and start now at the earliest. The period is at most 31 days and a closed room has no free slot.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "List the free time slots of a room",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the period, such as 2024-08-01T18:00:00Z",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the period, such as 2024-08-02T02:00:00Z",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Free time slots of the room",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TimeSlot"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or period supplied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/players": {
+            "get": {
+                "description": "Get the players in the room with their current level, in the order they joined.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "List the players in a room",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Players in the room",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RoomPlayer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID supplied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Adds the authenticated player to the room, or the player of player_id. Adding another player needs the rooms:players permission. Private rooms need their invite code or password, the LV of the player must be in the range of the room and the room must have a free place.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Add a player to a room",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Player to add, the authenticated player when left out",
+                        "name": "player",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.JoinRoom"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Player added",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request due to invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "player_id of another player without permission rooms:players, invalid invite code or password, or LV outside the range of the room",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Room or player not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Player already in the room, or room full",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/players/{playerId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes the player from the room. Players can leave rooms, removing another player needs the rooms:players permission.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Remove a player from a room",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Player ID",
+                        "name": "playerId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Player removed",
+                        "schema": {
+                            "$ref": "#/definitions/models.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID supplied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing permission rooms:players",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Player not in the room",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/rooms/{id}/transitions": {
+            "get": {
+                "description": "Get the latest transitions of the status of the room first, with the player or API key that made them and why.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "List the status history of a room",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Most transitions returned, 20 by default and at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transitions of the room",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.RoomTransition"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid ID or limit supplied",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Applies the event to the status of the room and records it in the history of the room. occupy moves an available room to occupied and release moves it back, start_maintenance puts a room that is not under maintenance under it, close closes an available room or one under maintenance and reopen makes a room under maintenance or closed available. occupy, release and close need the rooms:update permission, start_maintenance and reopen need rooms:maintenance.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rooms"
+                ],
+                "summary": "Change the status of a room",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Room ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Event and reason",
+                        "name": "transition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TransitionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Transition made",
+                        "schema": {
+                            "$ref": "#/definitions/models.RoomTransition"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request due to invalid input",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing permission of the event",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Room not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Event not allowed in the status of the room",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "Git commit and build time of the binary and the Go version it was built with.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Build information",
+                "responses": {
+                    "200": {
+                        "description": "Build information",
+                        "schema": {
+                            "$ref": "#/definitions/health.Version"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "health.CheckResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "number",
+                    "example": 1.25
+                },
+                "name": {
+                    "type": "string",
+                    "example": "database"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/health.CheckResult"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "health.Version": {
+            "type": "object",
+            "properties": {
+                "build_time": {
+                    "type": "string",
+                    "example": "2024-07-27T16:09:21Z"
+                },
+                "commit": {
+                    "type": "string",
+                    "example": "9fd67d9"
+                },
+                "go_version": {
+                    "type": "string",
+                    "example": "go1.20.14"
+                }
+            }
+        },
+        "models.CreateResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreateRoomResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "invite_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.JoinRoom": {
+            "type": "object",
+            "properties": {
+                "invite_code": {
+                    "description": "InviteCode or Password of a private room",
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "player_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PlayerRank": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "lv": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RescheduleReservation": {
+            "type": "object",
+            "required": [
+                "end_time",
+                "start_time"
+            ],
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
                 }
             }
         },
         "models.Reservation": {
             "type": "object",
             "required": [
-                "date",
+                "end_time",
                 "player_ids",
-                "room_id"
+                "room_id",
+                "start_time"
             ],
             "properties": {
-                "date": {
+                "end_time": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "player_ids": {
+                "invite_code": {
+                    "description": "InviteCode or Password of a private room",
                     "type": "string"
                 },
+                "password": {
+                    "type": "string"
+                },
+                "player_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
                 "room_id": {
                     "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
                 }
             }
         },
         "models.ReservationRoom": {
             "type": "object",
             "properties": {
-                "date": {
+                "checked_in_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "description": "CreatedBy is the player who made the reservation, left out when made with an API key",
+                    "type": "integer"
+                },
+                "end_time": {
                     "type": "string"
                 },
                 "id": {
@@ -411,9 +1480,34 @@ const docTemplate = `{
                 },
                 "room_id": {
                     "type": "integer"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.ReservationStatus"
                 }
             }
         },
+        "models.ReservationStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "confirmed",
+                "checked_in",
+                "completed",
+                "cancelled",
+                "no_show"
+            ],
+            "x-enum-varnames": [
+                "ReservationPending",
+                "ReservationConfirmed",
+                "ReservationCheckedIn",
+                "ReservationCompleted",
+                "ReservationCancelled",
+                "ReservationNoShow"
+            ]
+        },
         "models.Room": {
             "type": "object",
             "required": [
@@ -421,23 +1515,156 @@ const docTemplate = `{
                 "name"
             ],
             "properties": {
+                "capacity": {
+                    "description": "Capacity is the most players in the room, 0 for no limit",
+                    "type": "integer",
+                    "minimum": 0
+                },
                 "description": {
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
+                "invite_code": {
+                    "description": "InviteCode of a private room, only returned to the players allowed to update rooms",
+                    "type": "string"
+                },
+                "max_lv": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "min_lv": {
+                    "description": "MinLV and MaxLV bound the LV of the players joining the room when set",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "min_players": {
+                    "description": "MinPlayers is the fewest players a reservation of the room is made for",
+                    "type": "integer",
+                    "minimum": 0
+                },
                 "name": {
                     "type": "string"
                 },
-                "player_ids": {
+                "password": {
+                    "description": "Password of a private room, it is never returned",
                     "type": "string"
                 },
+                "players": {
+                    "description": "Players is the number of players in the room",
+                    "type": "integer"
+                },
+                "private": {
+                    "description": "Private rooms are joined with their invite code or password",
+                    "type": "boolean"
+                },
                 "status": {
                     "$ref": "#/definitions/models.Status"
                 }
             }
         },
+        "models.RoomEvent": {
+            "type": "string",
+            "enum": [
+                "occupy",
+                "release",
+                "start_maintenance",
+                "close",
+                "reopen"
+            ],
+            "x-enum-varnames": [
+                "EventOccupy",
+                "EventRelease",
+                "EventStartMaintenance",
+                "EventClose",
+                "EventReopen"
+            ]
+        },
+        "models.RoomPlayer": {
+            "type": "object",
+            "properties": {
+                "joined_at": {
+                    "type": "string"
+                },
+                "lv": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "player_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RoomTransition": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "$ref": "#/definitions/models.RoomEvent"
+                },
+                "from": {
+                    "$ref": "#/definitions/models.Status"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "room_id": {
+                    "type": "integer"
+                },
+                "to": {
+                    "$ref": "#/definitions/models.Status"
+                }
+            }
+        },
+        "models.RoomUpdate": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "description": "Join rules of the room, see Room",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_lv": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "min_lv": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "min_players": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "description": "Password of a private room, it is only changed when set",
+                    "type": "string"
+                },
+                "private": {
+                    "type": "boolean"
+                }
+            }
+        },
         "models.Status": {
             "type": "integer",
             "enum": [
@@ -455,6 +1682,57 @@ const docTemplate = `{
         },
         "models.SuccessResponse": {
             "type": "object"
+        },
+        "models.TimeSlot": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TransitionRequest": {
+            "type": "object",
+            "required": [
+                "event"
+            ],
+            "properties": {
+                "event": {
+                    "enum": [
+                        "occupy",
+                        "release",
+                        "start_maintenance",
+                        "close",
+                        "reopen"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.RoomEvent"
+                        }
+                    ]
+                },
+                "reason": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key of another service from POST /api_keys of playerManagementSystem",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "Access token from POST /auth/login of playerManagementSystem, as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
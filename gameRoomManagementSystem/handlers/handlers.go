@@ -2,21 +2,44 @@ package handlers
 
 import (
 	"database/sql"
+	"time"
 
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoomsRoutes(rooms *gin.RouterGroup, db *sql.DB) {
+// errorResponse is the body of a failed request, carrying its ID to find its logs.
+func errorResponse(c *gin.Context, message string) models.ErrorResponse {
+	return models.ErrorResponse{Error: message, RequestID: middleware.RequestID(c)}
+}
+
+// The reservations of a room keep buffer free between them.
+func SetupRoomsRoutes(rooms *gin.RouterGroup, db *sql.DB, limiter middleware.RateLimitStore, buffer time.Duration) {
 	// Player routes
+	rooms.Use(middleware.RateLimit(limiter, DefaultRateLimit))
 	rooms.GET("/", func(c *gin.Context) { GetRooms(c, db) })
-	rooms.POST("/", func(c *gin.Context) { CreateRoom(c, db) })
+	rooms.POST("/", Policy.Require(PermRoomsCreate), func(c *gin.Context) { CreateRoom(c, db) })
 	rooms.GET("/:id", func(c *gin.Context) { GetRoom(c, db) })
-	rooms.PUT("/:id", func(c *gin.Context) { UpdateRoom(c, db) })
-	rooms.DELETE("/:id", func(c *gin.Context) { DeleteRoom(c, db) })
+	rooms.PUT("/:id", Policy.Require(PermRoomsUpdate), func(c *gin.Context) { UpdateRoom(c, db) })
+	rooms.DELETE("/:id", Policy.Require(PermRoomsDelete), func(c *gin.Context) { DeleteRoom(c, db) })
+	rooms.GET("/:id/players", func(c *gin.Context) { GetRoomPlayers(c, db) })
+	rooms.POST("/:id/players", middleware.RequireAuth(), func(c *gin.Context) { AddRoomPlayer(c, db) })
+	rooms.DELETE("/:id/players/:playerId", Policy.RequireSelfOr("playerId", PermRoomsPlayers), func(c *gin.Context) { RemoveRoomPlayer(c, db) })
+	rooms.GET("/:id/availability", func(c *gin.Context) { GetRoomAvailability(c, db, buffer) })
+	rooms.GET("/:id/transitions", func(c *gin.Context) { GetRoomTransitions(c, db) })
+	rooms.POST("/:id/transitions", middleware.RequireAuth(), func(c *gin.Context) { TransitionRoom(c, db) })
 }
 
-func SetupReservationsRoutes(reservations *gin.RouterGroup, db *sql.DB) {
+// The check-in of the reservations opens buffer before their start and closes noShowAfter it.
+func SetupReservationsRoutes(reservations *gin.RouterGroup, db *sql.DB, limiter middleware.RateLimitStore, buffer, noShowAfter time.Duration) {
 	// Level routes
+	reservations.Use(middleware.RateLimit(limiter, DefaultRateLimit))
 	reservations.GET("/", func(c *gin.Context) { GetReservations(c, db) })
-	reservations.POST("/", func(c *gin.Context) { CreateReservations(c, db) })
+	reservations.POST("/", middleware.RequireAuth(), middleware.RateLimit(limiter, CreateReservationRateLimit), func(c *gin.Context) { CreateReservations(c, db, buffer) })
+	reservations.GET("/:id", func(c *gin.Context) { GetReservation(c, db) })
+	reservations.PUT("/:id", middleware.RequireAuth(), func(c *gin.Context) { UpdateReservation(c, db, buffer) })
+	reservations.DELETE("/:id", middleware.RequireAuth(), func(c *gin.Context) { CancelReservation(c, db) })
+	reservations.POST("/:id/confirm", Policy.Require(PermReservationsManage), func(c *gin.Context) { ConfirmReservation(c, db) })
+	reservations.POST("/:id/check-in", middleware.RequireAuth(), func(c *gin.Context) { CheckInReservation(c, db, buffer, noShowAfter) })
 }
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Domain metrics of the rooms and reservations routes.
+var (
+	reservationsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "reservations_created_total",
+		Help:      "Reservations created.",
+	})
+	roomTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "room_transitions_total",
+		Help:      "Transitions of the status of the rooms, by event.",
+	}, []string{"event"})
+)
+
+func init() {
+	prometheus.MustRegister(reservationsCreated, roomTransitions)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
+)
+
+// Permissions of the rooms and reservations routes.
+const (
+	PermRoomsCreate      = "rooms:create"
+	PermRoomsUpdate      = "rooms:update"
+	PermRoomsMaintenance = "rooms:maintenance"
+	PermRoomsDelete      = "rooms:delete"
+	PermRoomsPlayers     = "rooms:players"
+
+	PermReservationsManage = "reservations:manage"
+)
+
+// Policy grants the permissions to roles, every player can make reservations,
+// change their own and join or leave rooms.
+var Policy = middleware.Policy{
+	PermRoomsCreate:      {middleware.RoleGameMaster, middleware.RoleAdmin},
+	PermRoomsUpdate:      {middleware.RoleSupport, middleware.RoleGameMaster, middleware.RoleAdmin},
+	PermRoomsMaintenance: {middleware.RoleGameMaster, middleware.RoleAdmin},
+	PermRoomsDelete:      {middleware.RoleGameMaster, middleware.RoleAdmin},
+	PermRoomsPlayers:     {middleware.RoleSupport, middleware.RoleGameMaster, middleware.RoleAdmin},
+
+	PermReservationsManage: {middleware.RoleSupport, middleware.RoleGameMaster, middleware.RoleAdmin},
+}
+
+// eventPermissions are the permissions of the events changing the status of
+// the rooms, only game masters put rooms under maintenance and reopen them.
+var eventPermissions = map[models.RoomEvent]string{
+	models.EventOccupy:           PermRoomsUpdate,
+	models.EventRelease:          PermRoomsUpdate,
+	models.EventClose:            PermRoomsUpdate,
+	models.EventStartMaintenance: PermRoomsMaintenance,
+	models.EventReopen:           PermRoomsMaintenance,
+}
+
+// Rate limits of the routes, making reservations has a stricter one on top of the default.
+var (
+	DefaultRateLimit           = middleware.RateLimitPolicy{Name: "rooms", Limit: 120, Window: time.Minute}
+	CreateReservationRateLimit = middleware.RateLimitPolicy{Name: "reservations:create", Limit: 20, Window: time.Minute}
+)
@@ -2,12 +2,14 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/databases"
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
 	"github.com/gin-gonic/gin"
 )
 
@@ -32,9 +34,9 @@ func GetReservations(c *gin.Context, db *sql.DB) {
 	startDate, _ := time.Parse(time_format, c.Query("start_Date"))
 	endDate, _ := time.Parse(time_format, c.Query("end_Date"))
 	limit, _ := strconv.Atoi(c.Query("limit"))
-	reservations, err := databases.ListReservation(db, roomID, startDate, endDate, limit)
+	reservations, err := databases.ListReservation(c.Request.Context(), db, roomID, startDate, endDate, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
 	if len(reservations) > 1 {
@@ -45,68 +47,242 @@ func GetReservations(c *gin.Context, db *sql.DB) {
 	c.JSON(http.StatusOK, args)
 }
 
-func UpdateReservationRoom(db *sql.DB, roomID int, playerIDs string) error {
-	var room models.Room
-	room.ID = roomID
-	room.PlayerIDs = playerIDs
-	err := databases.UpdateRoomData(db, room)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
 // CreateReservations handles the creation of a new reservation for a room.
-// It processes the request to create a reservation by checking if the room is available,
-// validating the date format, inserting the reservation into the database, and updating the room with player IDs.
+// It processes the request to create a reservation by checking the time slot is free
+// and inserting the reservation with its players into the database.
 //
 // @Summary      Create a reservation
-// @Description  Creates a new reservation for a specified room if the room is available. The request body must include the room ID, date of reservation, and player IDs. If successful, returns the ID of the created reservation.
+// @Description  Creates a new pending reservation of a time slot of a room. The request body must include the room ID, the start and end times of the reservation in RFC 3339, and the IDs of the players, who are checked as they join the room, at most its capacity. Players reserve for themselves, the other players need the rooms:players permission. The slot must be in the future and must not overlap another reservation of the room, nor the buffer kept between two reservations, see GET /rooms/{id}/availability. Closed rooms cannot be reserved and private rooms need their invite code or password. If successful, returns the ID of the created reservation.
 // @Tags         reservations
 // @Accept       json
 // @Produce      json
 // @Param        reservation  body  models.Reservation  true  "Reservation details to be created"
 // @Success      201  {object}  models.CreateResponse "Reservation created successfully, returns the ID of the new reservation"
-// @Failure      400  {object}  models.ErrorResponse "Bad request due to invalid input or date format"
+// @Failure      400  {object}  models.ErrorResponse "Bad request due to invalid input or time slot"
+// @Failure      401  {object}  models.ErrorResponse "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse "Missing permission rooms:players, invalid invite code or password, or LV of a player outside the range of the room"
+// @Failure      404  {object}  models.ErrorResponse "Room or player not found"
+// @Failure      409  {object}  models.ErrorResponse "Time slot already reserved, room closed or more players than its capacity"
+// @Failure      429  {object}  models.ErrorResponse "Rate limit exceeded"
 // @Failure      500  {object}  models.ErrorResponse "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /reservations [post]
-func CreateReservations(c *gin.Context, db *sql.DB) {
+func CreateReservations(c *gin.Context, db *sql.DB, buffer time.Duration) {
 	var reservation models.Reservation
 	if err := c.ShouldBindJSON(&reservation); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
 		return
 	}
-	//check the old is available or not
-	room, err := databases.ShowRoom(db, reservation.RoomID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+	slot := models.TimeSlot{StartTime: reservation.StartTime.UTC(), EndTime: reservation.EndTime.UTC()}
+	if slot.StartTime.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "start_time must be in the future"))
 		return
 	}
 
-	if room.Status != models.StatusAvailable {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "this room is not available"})
+	// Players reserve for themselves, the other players need rooms:players
+	for _, playerID := range reservation.PlayerIDs {
+		if !middleware.IsPlayer(c, playerID) && !Policy.Allows(c, PermRoomsPlayers) {
+			c.JSON(http.StatusForbidden, errorResponse(c, "missing permission "+PermRoomsPlayers+" to reserve for other players"))
+			return
+		}
+	}
+
+	// Players need the invite code or password of a private room
+	if !allowRoomAccess(c, db, reservation.RoomID, reservation.InviteCode, reservation.Password) {
 		return
 	}
 
-	time, err := time.Parse(time_format, reservation.Date)
+	// The player making the reservation can change it, API keys need reservations:manage
+	createdBy, _ := middleware.PlayerID(c)
+	id, err := databases.InsertReservation(c.Request.Context(), db, reservation.RoomID, slot, buffer, reservation.PlayerIDs, createdBy)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "date format has issues"})
+		c.JSON(joinStatus(err), errorResponse(c, err.Error()))
 		return
 	}
 
-	id, err := databases.InsertReservation(db, reservation.RoomID, time)
+	reservationsCreated.Inc()
+	c.JSON(http.StatusCreated, models.CreateResponse{ID: id})
+}
 
+// @Summary      Retrieve a reservation by ID
+// @Description  Fetch the reservation with its time slot, status, the player who made it and its players.
+// @Tags         reservations
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Reservation ID"
+// @Success      200  {object}  models.ReservationRoom  "Details of the reservation"
+// @Failure      400  {object}  models.ErrorResponse    "Invalid ID supplied"
+// @Failure      404  {object}  models.ErrorResponse    "Reservation not found"
+// @Failure      500  {object}  models.ErrorResponse    "Internal server error"
+// @Router       /reservations/{id} [get]
+func GetReservation(c *gin.Context, db *sql.DB) {
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid reservation id"))
 		return
 	}
+	reservation, err := databases.ShowReservation(c.Request.Context(), db, id)
+	if errors.Is(err, databases.ErrReservationNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, reservation)
+}
 
-	err = UpdateReservationRoom(db, reservation.RoomID, reservation.PlayerIDs)
+// @Summary      Reschedule a reservation
+// @Description  Moves a pending or confirmed reservation to another time slot of its room, in the future and free of the other reservations and the buffer kept between them. Players reschedule the reservations they made, the others need the reservations:manage permission.
+// @Tags         reservations
+// @Accept       json
+// @Produce      json
+// @Param        id    path  int                           true  "Reservation ID"
+// @Param        slot  body  models.RescheduleReservation  true  "New time slot"
+// @Success      200  {object}  models.SuccessResponse "Reservation rescheduled"
+// @Failure      400  {object}  models.ErrorResponse   "Bad request due to invalid input or time slot"
+// @Failure      401  {object}  models.ErrorResponse   "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse   "Reservation of another player without permission reservations:manage"
+// @Failure      404  {object}  models.ErrorResponse   "Reservation not found"
+// @Failure      409  {object}  models.ErrorResponse   "Time slot already reserved, or reservation no longer pending or confirmed"
+// @Failure      500  {object}  models.ErrorResponse   "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /reservations/{id} [put]
+func UpdateReservation(c *gin.Context, db *sql.DB, buffer time.Duration) {
+	id, ok := allowReservation(c, db)
+	if !ok {
+		return
+	}
+	var reschedule models.RescheduleReservation
+	if err := c.ShouldBindJSON(&reschedule); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+	slot := models.TimeSlot{StartTime: reschedule.StartTime.UTC(), EndTime: reschedule.EndTime.UTC()}
+	if slot.StartTime.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "start_time must be in the future"))
+		return
+	}
 
+	err := databases.RescheduleReservation(c.Request.Context(), db, id, slot, buffer)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		c.JSON(joinStatus(err), errorResponse(c, err.Error()))
 		return
 	}
+	c.JSON(http.StatusOK, models.SuccessResponse{})
+}
 
-	c.JSON(http.StatusCreated, models.CreateResponse{ID: id})
+// @Summary      Cancel a reservation
+// @Description  Cancels a pending or confirmed reservation, its time slot is free again. Players cancel the reservations they made, the others need the reservations:manage permission.
+// @Tags         reservations
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Reservation ID"
+// @Success      200  {object}  models.SuccessResponse "Reservation cancelled"
+// @Failure      400  {object}  models.ErrorResponse   "Invalid ID supplied"
+// @Failure      401  {object}  models.ErrorResponse   "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse   "Reservation of another player without permission reservations:manage"
+// @Failure      404  {object}  models.ErrorResponse   "Reservation not found"
+// @Failure      409  {object}  models.ErrorResponse   "Reservation no longer pending or confirmed"
+// @Failure      500  {object}  models.ErrorResponse   "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /reservations/{id} [delete]
+func CancelReservation(c *gin.Context, db *sql.DB) {
+	id, ok := allowReservation(c, db)
+	if !ok {
+		return
+	}
+	if err := databases.CancelReservation(c.Request.Context(), db, id); err != nil {
+		c.JSON(joinStatus(err), errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse{})
+}
+
+// @Summary      Confirm a reservation
+// @Description  Confirms a pending reservation.
+// @Tags         reservations
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Reservation ID"
+// @Success      200  {object}  models.SuccessResponse "Reservation confirmed"
+// @Failure      400  {object}  models.ErrorResponse   "Invalid ID supplied"
+// @Failure      401  {object}  models.ErrorResponse   "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse   "Missing permission reservations:manage"
+// @Failure      404  {object}  models.ErrorResponse   "Reservation not found"
+// @Failure      409  {object}  models.ErrorResponse   "Reservation not pending"
+// @Failure      500  {object}  models.ErrorResponse   "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /reservations/{id}/confirm [post]
+func ConfirmReservation(c *gin.Context, db *sql.DB) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid reservation id"))
+		return
+	}
+	if err := databases.ConfirmReservation(c.Request.Context(), db, id); err != nil {
+		c.JSON(joinStatus(err), errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse{})
+}
+
+// @Summary      Check in a reservation
+// @Description  Checks in a pending or confirmed reservation when its players arrive and occupies its room, recorded in the status history of the room. The check-in opens the reservation buffer before the start of the reservation and closes when the reservation becomes a no show. The room must be available, a reservation of the room that ended is completed first. Players check in the reservations they made, the others need the reservations:manage permission.
+// @Tags         reservations
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Reservation ID"
+// @Success      200  {object}  models.SuccessResponse "Reservation checked in"
+// @Failure      400  {object}  models.ErrorResponse   "Invalid ID supplied"
+// @Failure      401  {object}  models.ErrorResponse   "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse   "Reservation of another player without permission reservations:manage"
+// @Failure      404  {object}  models.ErrorResponse   "Reservation not found"
+// @Failure      409  {object}  models.ErrorResponse   "Outside the check-in window, reservation no longer pending or confirmed, or room not available"
+// @Failure      500  {object}  models.ErrorResponse   "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /reservations/{id}/check-in [post]
+func CheckInReservation(c *gin.Context, db *sql.DB, buffer, noShowAfter time.Duration) {
+	id, ok := allowReservation(c, db)
+	if !ok {
+		return
+	}
+	err := databases.CheckInReservation(c.Request.Context(), db, id, time.Now().UTC(), buffer, noShowAfter, requestActor(c))
+	if err != nil {
+		c.JSON(joinStatus(err), errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse{})
+}
+
+// allowReservation lets the player who made the reservation of the id path
+// parameter and the callers with reservations:manage change it. It responds
+// with the error and returns false otherwise.
+func allowReservation(c *gin.Context, db *sql.DB) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid reservation id"))
+		return 0, false
+	}
+	if Policy.Allows(c, PermReservationsManage) {
+		return id, true
+	}
+	reservation, err := databases.ShowReservation(c.Request.Context(), db, id)
+	if errors.Is(err, databases.ErrReservationNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return 0, false
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return 0, false
+	}
+	if reservation.CreatedBy == nil || !middleware.IsPlayer(c, *reservation.CreatedBy) {
+		c.JSON(http.StatusForbidden, errorResponse(c, "missing permission "+PermReservationsManage+" to change the reservations of other players"))
+		return 0, false
+	}
+	return id, true
 }
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/auth"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/databases"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      List the players in a room
+// @Description  Get the players in the room with their current level, in the order they joined.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Room ID"
+// @Success      200  {object}  []models.RoomPlayer  "Players in the room"
+// @Failure      400  {object}  models.ErrorResponse "Invalid ID supplied"
+// @Failure      404  {object}  models.ErrorResponse "Room not found"
+// @Failure      500  {object}  models.ErrorResponse "Internal server error"
+// @Router       /rooms/{id}/players [get]
+func GetRoomPlayers(c *gin.Context, db *sql.DB) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid room id"))
+		return
+	}
+	players, err := databases.ListRoomPlayers(c.Request.Context(), db, id)
+	if errors.Is(err, databases.ErrRoomNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, players)
+}
+
+// @Summary      Add a player to a room
+// @Description  Adds the authenticated player to the room, or the player of player_id. Adding another player needs the rooms:players permission. Private rooms need their invite code or password, the LV of the player must be in the range of the room and the room must have a free place.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param        id      path  int              true  "Room ID"
+// @Param        player  body  models.JoinRoom  false "Player to add, the authenticated player when left out"
+// @Success      201  {object}  models.SuccessResponse "Player added"
+// @Failure      400  {object}  models.ErrorResponse   "Bad request due to invalid input"
+// @Failure      401  {object}  models.ErrorResponse   "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse   "player_id of another player without permission rooms:players, invalid invite code or password, or LV outside the range of the room"
+// @Failure      404  {object}  models.ErrorResponse   "Room or player not found"
+// @Failure      409  {object}  models.ErrorResponse   "Player already in the room, or room full"
+// @Failure      500  {object}  models.ErrorResponse   "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/players [post]
+func AddRoomPlayer(c *gin.Context, db *sql.DB) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid room id"))
+		return
+	}
+	var join models.JoinRoom
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&join); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+			return
+		}
+	}
+
+	// The authenticated player joins, API keys must name the player
+	if playerID, ok := middleware.PlayerID(c); ok && join.PlayerID == 0 {
+		join.PlayerID = playerID
+	}
+	if join.PlayerID == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "player_id is required"))
+		return
+	} else if !middleware.IsPlayer(c, join.PlayerID) && !Policy.Allows(c, PermRoomsPlayers) {
+		c.JSON(http.StatusForbidden, errorResponse(c, "missing permission "+PermRoomsPlayers+" to add other players"))
+		return
+	}
+
+	// Players need the invite code or password of a private room
+	if !allowRoomAccess(c, db, id, join.InviteCode, join.Password) {
+		return
+	}
+
+	err = databases.AddRoomPlayer(c.Request.Context(), db, id, join.PlayerID)
+	if err != nil {
+		c.JSON(joinStatus(err), errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, models.SuccessResponse{})
+}
+
+// allowRoomAccess checks the invite code or password of a private room, the
+// players allowed to manage the players of the rooms do not need them. It
+// responds with the error and returns false when the access is denied.
+func allowRoomAccess(c *gin.Context, db *sql.DB, roomID int, inviteCode, password string) bool {
+	if Policy.Allows(c, PermRoomsPlayers) {
+		return true
+	}
+	private, code, passwordHash, err := databases.ShowRoomSecret(c.Request.Context(), db, roomID)
+	if errors.Is(err, databases.ErrRoomNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return false
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return false
+	}
+	if !private {
+		return true
+	}
+	if err := auth.CheckRoomSecret(code, passwordHash, inviteCode, password); err != nil {
+		c.JSON(http.StatusForbidden, errorResponse(c, err.Error()))
+		return false
+	}
+	return true
+}
+
+// joinStatus is the status code of the errors of players joining a room and
+// of the reservations of its time slots.
+func joinStatus(err error) int {
+	switch {
+	case errors.Is(err, databases.ErrRoomNotFound), errors.Is(err, databases.ErrPlayerNotFound),
+		errors.Is(err, databases.ErrReservationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, databases.ErrAlreadyInRoom), errors.Is(err, databases.ErrRoomFull),
+		errors.Is(err, databases.ErrSlotTaken), errors.Is(err, databases.ErrRoomClosed),
+		errors.Is(err, databases.ErrReservationStatus), errors.Is(err, databases.ErrCheckInWindow),
+		errors.Is(err, databases.ErrIllegalTransition):
+		return http.StatusConflict
+	case errors.Is(err, databases.ErrLevelOutOfRange):
+		return http.StatusForbidden
+	case errors.Is(err, databases.ErrTooFewPlayers):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// @Summary      Remove a player from a room
+// @Description  Removes the player from the room. Players can leave rooms, removing another player needs the rooms:players permission.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param        id        path  int  true  "Room ID"
+// @Param        playerId  path  int  true  "Player ID"
+// @Success      200  {object}  models.SuccessResponse "Player removed"
+// @Failure      400  {object}  models.ErrorResponse   "Invalid ID supplied"
+// @Failure      401  {object}  models.ErrorResponse   "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse   "Missing permission rooms:players"
+// @Failure      404  {object}  models.ErrorResponse   "Player not in the room"
+// @Failure      500  {object}  models.ErrorResponse   "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/players/{playerId} [delete]
+func RemoveRoomPlayer(c *gin.Context, db *sql.DB) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid room id"))
+		return
+	}
+	playerID, err := strconv.Atoi(c.Param("playerId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid player id"))
+		return
+	}
+
+	err = databases.RemoveRoomPlayer(c.Request.Context(), db, id, playerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(c, "player is not in the room"))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse{})
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/databases"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRoomTransitions bounds the history returned by GetRoomTransitions.
+const maxRoomTransitions = 100
+
+// @Summary      Change the status of a room
+// @Description  Applies the event to the status of the room and records it in the history of the room. occupy moves an available room to occupied and release moves it back, start_maintenance puts a room that is not under maintenance under it, close closes an available room or one under maintenance and reopen makes a room under maintenance or closed available. occupy, release and close need the rooms:update permission, start_maintenance and reopen need rooms:maintenance.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param        id          path  int                       true  "Room ID"
+// @Param        transition  body  models.TransitionRequest  true  "Event and reason"
+// @Success      201  {object}  models.RoomTransition  "Transition made"
+// @Failure      400  {object}  models.ErrorResponse   "Bad request due to invalid input"
+// @Failure      401  {object}  models.ErrorResponse   "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse   "Missing permission of the event"
+// @Failure      404  {object}  models.ErrorResponse   "Room not found"
+// @Failure      409  {object}  models.ErrorResponse   "Event not allowed in the status of the room"
+// @Failure      500  {object}  models.ErrorResponse   "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /rooms/{id}/transitions [post]
+func TransitionRoom(c *gin.Context, db *sql.DB) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid room id"))
+		return
+	}
+	var request models.TransitionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+	if permission := eventPermissions[request.Event]; !Policy.Allows(c, permission) {
+		c.JSON(http.StatusForbidden, errorResponse(c, "missing permission "+permission+" to "+string(request.Event)+" a room"))
+		return
+	}
+
+	transition, err := databases.TransitionRoom(c.Request.Context(), db, id, request.Event, requestActor(c), request.Reason)
+	if errors.Is(err, databases.ErrRoomNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if errors.Is(err, databases.ErrIllegalTransition) {
+		c.JSON(http.StatusConflict, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	roomTransitions.WithLabelValues(string(transition.Event)).Inc()
+	c.JSON(http.StatusCreated, transition)
+}
+
+// @Summary      List the status history of a room
+// @Description  Get the latest transitions of the status of the room first, with the player or API key that made them and why.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param        id     path   int  true   "Room ID"
+// @Param        limit  query  int  false  "Most transitions returned, 20 by default and at most 100"
+// @Success      200  {object}  []models.RoomTransition  "Transitions of the room"
+// @Failure      400  {object}  models.ErrorResponse     "Invalid ID or limit supplied"
+// @Failure      404  {object}  models.ErrorResponse     "Room not found"
+// @Failure      500  {object}  models.ErrorResponse     "Internal server error"
+// @Router       /rooms/{id}/transitions [get]
+func GetRoomTransitions(c *gin.Context, db *sql.DB) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid room id"))
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > maxRoomTransitions {
+		c.JSON(http.StatusBadRequest, errorResponse(c, fmt.Sprintf("limit must be between 1 and %d", maxRoomTransitions)))
+		return
+	}
+
+	transitions, err := databases.ListRoomTransitions(c.Request.Context(), db, id, limit)
+	if errors.Is(err, databases.ErrRoomNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, transitions)
+}
+
+// requestActor names the player or API key making the request in the histories.
+func requestActor(c *gin.Context) string {
+	if id, ok := middleware.PlayerID(c); ok {
+		return fmt.Sprintf("player:%d", id)
+	}
+	if id, ok := middleware.APIKeyID(c); ok {
+		return fmt.Sprintf("api_key:%d", id)
+	}
+	return "anonymous"
+}
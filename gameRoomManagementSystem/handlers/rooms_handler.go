@@ -2,9 +2,13 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/auth"
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/databases"
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/gameRoomManagementSystem/models"
 
@@ -12,7 +16,7 @@ import (
 )
 
 // @Summary      Retrieve all rooms
-// @Description  Get a list of all rooms available in the database along with their details such as name, status, description, and player IDs.
+// @Description  Get a list of all rooms available in the database along with their details such as name and status.
 // @Tags         rooms
 // @Accept       json
 // @Produce      json
@@ -20,41 +24,55 @@ import (
 // @Failure      500  {object}  models.ErrorResponse  "Internal server error"
 // @Router       /rooms [get]
 func GetRooms(c *gin.Context, db *sql.DB) {
-	rooms, err := databases.ListRooms(db)
+	rooms, err := databases.ListRooms(c.Request.Context(), db)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
 	c.JSON(http.StatusOK, rooms)
 }
 
 // @Summary      Create a new room
-// @Description  Add a new room to the database with the provided name, description, and status. PlayerIDs are optional and can be set later.
+// @Description  Add a new room to the database with the provided name, description and join rules: the capacity, the fewest players of a reservation, the LV range of the players and whether the room is private. Private rooms get an invite code, returned once, and can have a password. Players join it with POST /rooms/{id}/players.
 // @Tags         rooms
 // @Accept       json
 // @Produce      json
 // @Param        room  body  models.Room  true  "Room details to be created"
-// @Success      201  {object}  models.CreateResponse "ID of the created room"
+// @Success      201  {object}  models.CreateRoomResponse "ID of the created room and the invite code of a private room"
 // @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid input"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission rooms:create"
 // @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /rooms [post]
 func CreateRoom(c *gin.Context, db *sql.DB) {
 	var room models.Room
 	if err := c.BindJSON(&room); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
 		return
 	}
 
-	id, err := databases.AddRoom(db, room.Name, room.Description)
+	if err := validateRoomRules(room); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+	passwordHash, inviteCode, err := roomSecret(room)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
-	c.JSON(http.StatusCreated, models.CreateResponse{ID: id})
+
+	id, err := databases.AddRoom(c.Request.Context(), db, room, passwordHash, inviteCode)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, models.CreateRoomResponse{ID: id, InviteCode: inviteCode})
 }
 
 // @Summary      Retrieve a room by ID
-// @Description  Fetch details of a specific room from the database identified by its ID. Returns room details including name, status, description, and player IDs.
+// @Description  Fetch details of a specific room from the database identified by its ID. Returns room details including name, status, join rules and number of players, and the invite code of a private room to the players allowed to update rooms.
 // @Tags         rooms
 // @Accept       json
 // @Produce      json
@@ -65,34 +83,64 @@ func CreateRoom(c *gin.Context, db *sql.DB) {
 // @Router       /rooms/{id} [get]
 func GetRoom(c *gin.Context, db *sql.DB) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	room, err := databases.ShowRoom(db, id)
+	room, err := databases.ShowRoom(c.Request.Context(), db, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
+	// The invite code is shared by the players managing the room
+	if room.Private && Policy.Allows(c, PermRoomsUpdate) {
+		_, room.InviteCode, _, err = databases.ShowRoomSecret(c.Request.Context(), db, id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+			return
+		}
+	}
 	c.JSON(http.StatusOK, room)
 }
 
 // @Summary      Update a room
-// @Description  Update the details of an existing room in the database. The request body should include the room's ID, name, status, description, and player IDs. The ID is used to identify the room to be updated.
+// @Description  Update the details of an existing room in the database. The request body should include the room's ID and the fields to change, the name, description and join rules left out keep their value. A private room keeps its invite code, or gets one, and its password unless a new one is given. The ID is used to identify the room to be updated. The status is changed with POST /rooms/{id}/transitions.
 // @Tags         rooms
 // @Accept       json
 // @Produce      json
-// @Param        room  body  models.Room  true  "Room details to be updated"
+// @Param        room  body  models.RoomUpdate  true  "Room details to be updated"
 // @Success      200  {object}  models.SuccessResponse "Update successful"
 // @Failure      400  {object}  models.ErrorResponse   "Bad request due to invalid input"
+// @Failure      401  {object}  models.ErrorResponse   "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse   "Missing permission rooms:update"
+// @Failure      404  {object}  models.ErrorResponse   "Room not found"
 // @Failure      500  {object}  models.ErrorResponse   "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /rooms [put]
 func UpdateRoom(c *gin.Context, db *sql.DB) {
-	var room models.Room
-	if err := c.BindJSON(&room); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+	var update models.RoomUpdate
+	if err := c.BindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
 		return
 	}
-	err := databases.UpdateRoomData(db, room)
-
+	// The invite code is only given to a room which is private after the update
+	passwordHash, inviteCode, err := roomSecret(models.Room{Private: true, Password: update.Password})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+
+	// The rules are checked with the values the request leaves out
+	var invalid error
+	err = databases.UpdateRoomData(c.Request.Context(), db, update, passwordHash, inviteCode, func(room models.Room) error {
+		invalid = validateRoomRules(room)
+		return invalid
+	})
+	if invalid != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, invalid.Error()))
+		return
+	} else if errors.Is(err, databases.ErrRoomNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
 	c.JSON(http.StatusOK, models.SuccessResponse{})
@@ -106,14 +154,112 @@ func UpdateRoom(c *gin.Context, db *sql.DB) {
 // @Param        id  path  int  true  "Room ID"
 // @Success      200  {object}  models.SuccessResponse "Delete successful"
 // @Failure      400  {object}  models.ErrorResponse   "Invalid ID supplied"
+// @Failure      401  {object}  models.ErrorResponse   "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse   "Missing permission rooms:delete"
 // @Failure      500  {object}  models.ErrorResponse   "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /rooms/{id} [delete]
 func DeleteRoom(c *gin.Context, db *sql.DB) {
 	id, _ := strconv.Atoi(c.Param("id"))
-	err := databases.DeleteRoom(db, id)
+	err := databases.DeleteRoom(c.Request.Context(), db, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
 	c.JSON(http.StatusOK, models.SuccessResponse{})
 }
+
+// maxAvailabilityRange bounds the period of GetRoomAvailability.
+const maxAvailabilityRange = 31 * 24 * time.Hour
+
+// @Summary      List the free time slots of a room
+// @Description  Get the free time slots of the room between from and to, in RFC 3339, a reservation fits in any part of them. The slots are cut short by the reservations of the room and the buffer kept between two reservations, and start now at the earliest. The period is at most 31 days and a closed room has no free slot.
+// @Tags         rooms
+// @Accept       json
+// @Produce      json
+// @Param        id    path   int     true  "Room ID"
+// @Param        from  query  string  true  "Start of the period, such as 2024-08-01T18:00:00Z"
+// @Param        to    query  string  true  "End of the period, such as 2024-08-02T02:00:00Z"
+// @Success      200  {object}  []models.TimeSlot     "Free time slots of the room"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid ID or period supplied"
+// @Failure      404  {object}  models.ErrorResponse  "Room not found"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Router       /rooms/{id}/availability [get]
+func GetRoomAvailability(c *gin.Context, db *sql.DB, buffer time.Duration) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid room id"))
+		return
+	}
+	from, err := time.Parse(time.RFC3339, c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "from must be a time in RFC 3339"))
+		return
+	}
+	to, err := time.Parse(time.RFC3339, c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "to must be a time in RFC 3339"))
+		return
+	}
+	if !to.After(from) || to.Sub(from) > maxAvailabilityRange {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "to must be after from and at most 31 days later"))
+		return
+	}
+
+	// The past cannot be reserved
+	from, to = from.UTC(), to.UTC()
+	if now := time.Now().UTC().Truncate(time.Second); from.Before(now) {
+		from = minTime(now, to)
+	}
+	free, err := databases.RoomAvailability(c.Request.Context(), db, id, from, to, buffer)
+	if errors.Is(err, databases.ErrRoomNotFound) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, free)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// validateRoomRules checks the join rules of a room are consistent.
+func validateRoomRules(room models.Room) error {
+	if room.MinLV != nil && room.MaxLV != nil && *room.MinLV > *room.MaxLV {
+		return fmt.Errorf("min_lv must not be greater than max_lv")
+	}
+	if room.Capacity > 0 && room.MinPlayers > room.Capacity {
+		return fmt.Errorf("min_players must not be greater than capacity")
+	}
+	if room.Password != "" && !room.Private {
+		return fmt.Errorf("only private rooms have a password")
+	}
+	return nil
+}
+
+// roomSecret returns the hash of the password of a private room and a new
+// invite code, used when the room has none.
+func roomSecret(room models.Room) (string, string, error) {
+	if !room.Private {
+		return "", "", nil
+	}
+	var passwordHash string
+	if room.Password != "" {
+		var err error
+		if passwordHash, err = auth.HashPassword(room.Password); err != nil {
+			return "", "", err
+		}
+	}
+	inviteCode, err := auth.NewInviteCode()
+	if err != nil {
+		return "", "", err
+	}
+	return passwordHash, inviteCode, nil
+}
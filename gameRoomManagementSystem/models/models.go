@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Status int
 
 const (
@@ -15,7 +17,113 @@ type Room struct {
 	Name        string `json:"name" binding:"required"`
 	Status      Status `json:"status"`
 	Description string `json:"description" binding:"required"`
-	PlayerIDs   string `json:"player_ids"`
+	// Capacity is the most players in the room, 0 for no limit
+	Capacity int `json:"capacity" binding:"min=0"`
+	// MinPlayers is the fewest players a reservation of the room is made for
+	MinPlayers int `json:"min_players" binding:"min=0"`
+	// MinLV and MaxLV bound the LV of the players joining the room when set
+	MinLV *int `json:"min_lv,omitempty" binding:"omitempty,min=0"`
+	MaxLV *int `json:"max_lv,omitempty" binding:"omitempty,min=0"`
+	// Private rooms are joined with their invite code or password
+	Private bool `json:"private"`
+	// Password of a private room, it is never returned
+	Password string `json:"password,omitempty"`
+	// InviteCode of a private room, only returned to the players allowed to update rooms
+	InviteCode string `json:"invite_code,omitempty"`
+	// Players is the number of players in the room
+	Players int `json:"players"`
+}
+
+// RoomUpdate is the request to update a room, the fields left out keep their value.
+type RoomUpdate struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Join rules of the room, see Room
+	Capacity   *int  `json:"capacity,omitempty" binding:"omitempty,min=0"`
+	MinPlayers *int  `json:"min_players,omitempty" binding:"omitempty,min=0"`
+	MinLV      *int  `json:"min_lv,omitempty" binding:"omitempty,min=0"`
+	MaxLV      *int  `json:"max_lv,omitempty" binding:"omitempty,min=0"`
+	Private    *bool `json:"private,omitempty"`
+	// Password of a private room, it is only changed when set
+	Password string `json:"password,omitempty"`
+}
+
+// Apply sets the fields of the update on the room.
+func (u RoomUpdate) Apply(room *Room) {
+	if u.Name != "" {
+		room.Name = u.Name
+	}
+	if u.Description != "" {
+		room.Description = u.Description
+	}
+	if u.Capacity != nil {
+		room.Capacity = *u.Capacity
+	}
+	if u.MinPlayers != nil {
+		room.MinPlayers = *u.MinPlayers
+	}
+	if u.MinLV != nil {
+		room.MinLV = u.MinLV
+	}
+	if u.MaxLV != nil {
+		room.MaxLV = u.MaxLV
+	}
+	if u.Private != nil {
+		room.Private = *u.Private
+	}
+	room.Password = u.Password
+}
+
+// RoomTransition is a change of the status of a room, kept in its history.
+type RoomTransition struct {
+	ID        int64     `json:"id"`
+	RoomID    int       `json:"room_id"`
+	Event     RoomEvent `json:"event"`
+	From      Status    `json:"from"`
+	To        Status    `json:"to"`
+	Actor     string    `json:"actor"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TransitionRequest is the event to apply to a room and why.
+type TransitionRequest struct {
+	Event  RoomEvent `json:"event" binding:"required,oneof=occupy release start_maintenance close reopen"`
+	Reason string    `json:"reason" binding:"max=255"`
+}
+
+// RoomPlayer is a player in a room, with the level the player has now.
+type RoomPlayer struct {
+	PlayerID int       `json:"player_id"`
+	Name     string    `json:"name"`
+	LV       int       `json:"lv"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// JoinRoom is the request to add a player to a room, the authenticated player
+// when player_id is left out.
+type JoinRoom struct {
+	PlayerID int `json:"player_id"`
+	// InviteCode or Password of a private room
+	InviteCode string `json:"invite_code"`
+	Password   string `json:"password"`
+}
+
+// RoomPlayersMigration reports the conversion of the comma separated
+// Room.PlayerIDs to RoomPlayer rows.
+type RoomPlayersMigration struct {
+	Rooms    int                `json:"rooms"`
+	Players  int                `json:"players"`
+	Unparsed []UnparsedPlayerID `json:"unparsed"`
+}
+
+// UnparsedPlayerID is an entry of Room.PlayerIDs that could not be converted,
+// it is left in the column.
+type UnparsedPlayerID struct {
+	RoomID int    `json:"room_id"`
+	Entry  string `json:"entry"`
+	Reason string `json:"reason"`
 }
 
 // struct for Completed the ReservationRoom
@@ -27,23 +135,46 @@ type PlayerRank struct {
 
 // struct for Reservation request
 type Reservation struct {
-	ID        int    `json:"id"`
-	RoomID    int    `json:"room_id" binding:"required"`
-	Date      string `json:"date" binding:"required"`
-	PlayerIDs string `json:"player_ids" binding:"required"`
+	ID        int       `json:"id"`
+	RoomID    int       `json:"room_id" binding:"required"`
+	StartTime time.Time `json:"start_time" binding:"required"`
+	EndTime   time.Time `json:"end_time" binding:"required,gtfield=StartTime"`
+	PlayerIDs []int     `json:"player_ids" binding:"required,min=1"`
+	// InviteCode or Password of a private room
+	InviteCode string `json:"invite_code"`
+	Password   string `json:"password"`
 }
 
 // return struct for Reservation
 type ReservationRoom struct {
-	ID     int          `json:"id"`
-	RoomID int          `json:"room_id"`
-	Date   string       `json:"date"`
-	Player []PlayerRank `json:"player"`
+	ID        int               `json:"id"`
+	RoomID    int               `json:"room_id"`
+	StartTime time.Time         `json:"start_time"`
+	EndTime   time.Time         `json:"end_time"`
+	Status    ReservationStatus `json:"status"`
+	// CreatedBy is the player who made the reservation, left out when made with an API key
+	CreatedBy   *int         `json:"created_by,omitempty"`
+	CheckedInAt *time.Time   `json:"checked_in_at,omitempty"`
+	Player      []PlayerRank `json:"player"`
 }
 
-// ErrorResponse represents an error response with a single error message.
+// RescheduleReservation moves a reservation to another time slot of its room.
+type RescheduleReservation struct {
+	StartTime time.Time `json:"start_time" binding:"required"`
+	EndTime   time.Time `json:"end_time" binding:"required,gtfield=StartTime"`
+}
+
+// TimeSlot is a period of time of a room, from StartTime until EndTime.
+type TimeSlot struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// ErrorResponse represents an error response with a single error message and
+// the ID of the request, to find its logs.
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // CreateResponse represents an id after created a item.
@@ -51,6 +182,12 @@ type CreateResponse struct {
 	ID int `json:"id"`
 }
 
+// CreateRoomResponse represents the id of a created room and the invite code of a private room.
+type CreateRoomResponse struct {
+	ID         int    `json:"id"`
+	InviteCode string `json:"invite_code,omitempty"`
+}
+
 // SuccessResponse represents an any after update or delete item.
 type SuccessResponse struct {
 }
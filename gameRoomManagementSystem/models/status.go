@@ -0,0 +1,75 @@
+package models
+
+// RoomEvent changes the status of a room, see Status.Next.
+type RoomEvent string
+
+const (
+	EventOccupy           RoomEvent = "occupy"
+	EventRelease          RoomEvent = "release"
+	EventStartMaintenance RoomEvent = "start_maintenance"
+	EventClose            RoomEvent = "close"
+	EventReopen           RoomEvent = "reopen"
+)
+
+// roomTransitions are the statuses each event moves a room from, and to.
+var roomTransitions = map[RoomEvent]struct {
+	from []Status
+	to   Status
+}{
+	EventOccupy:           {from: []Status{StatusAvailable}, to: StatusOccupied},
+	EventRelease:          {from: []Status{StatusOccupied}, to: StatusAvailable},
+	EventStartMaintenance: {from: []Status{StatusAvailable, StatusOccupied, StatusClosed}, to: StatusMaintenance},
+	EventClose:            {from: []Status{StatusAvailable, StatusMaintenance}, to: StatusClosed},
+	EventReopen:           {from: []Status{StatusMaintenance, StatusClosed}, to: StatusAvailable},
+}
+
+// Next returns the status a room in s moves to on event, false when the
+// event is unknown or not allowed in s.
+func (s Status) Next(event RoomEvent) (Status, bool) {
+	transition, ok := roomTransitions[event]
+	if !ok {
+		return s, false
+	}
+	for _, from := range transition.from {
+		if from == s {
+			return transition.to, true
+		}
+	}
+	return s, false
+}
+
+// String is the name of the status in the errors and the history.
+func (s Status) String() string {
+	switch s {
+	case StatusAvailable:
+		return "available"
+	case StatusOccupied:
+		return "occupied"
+	case StatusMaintenance:
+		return "maintenance"
+	case StatusClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// ReservationStatus is where a reservation is in its lifecycle. A reservation
+// is pending until confirmed, checked in when its players arrive and then
+// completed once it ends. It is cancelled when called off and a no show when
+// nobody checked in on time.
+type ReservationStatus string
+
+const (
+	ReservationPending   ReservationStatus = "pending"
+	ReservationConfirmed ReservationStatus = "confirmed"
+	ReservationCheckedIn ReservationStatus = "checked_in"
+	ReservationCompleted ReservationStatus = "completed"
+	ReservationCancelled ReservationStatus = "cancelled"
+	ReservationNoShow    ReservationStatus = "no_show"
+)
+
+// Upcoming reports whether the reservation can still be changed, cancelled or
+// checked in.
+func (s ReservationStatus) Upcoming() bool {
+	return s == ReservationPending || s == ReservationConfirmed
+}
@@ -0,0 +1,36 @@
+package models
+
+import "testing"
+
+func TestStatusNext(t *testing.T) {
+	tests := []struct {
+		from  Status
+		event RoomEvent
+		to    Status
+		ok    bool
+	}{
+		{StatusAvailable, EventOccupy, StatusOccupied, true},
+		{StatusOccupied, EventRelease, StatusAvailable, true},
+		{StatusAvailable, EventStartMaintenance, StatusMaintenance, true},
+		{StatusOccupied, EventStartMaintenance, StatusMaintenance, true},
+		{StatusClosed, EventStartMaintenance, StatusMaintenance, true},
+		{StatusAvailable, EventClose, StatusClosed, true},
+		{StatusMaintenance, EventClose, StatusClosed, true},
+		{StatusMaintenance, EventReopen, StatusAvailable, true},
+		{StatusClosed, EventReopen, StatusAvailable, true},
+
+		{StatusOccupied, EventOccupy, StatusOccupied, false},
+		{StatusAvailable, EventRelease, StatusAvailable, false},
+		{StatusMaintenance, EventStartMaintenance, StatusMaintenance, false},
+		{StatusOccupied, EventClose, StatusOccupied, false},
+		{StatusAvailable, EventReopen, StatusAvailable, false},
+		{StatusClosed, EventOccupy, StatusClosed, false},
+		{StatusAvailable, RoomEvent("demolish"), StatusAvailable, false},
+	}
+	for _, tt := range tests {
+		to, ok := tt.from.Next(tt.event)
+		if to != tt.to || ok != tt.ok {
+			t.Errorf("%s.Next(%s) = %s, %v, want %s, %v", tt.from, tt.event, to, ok, tt.to, tt.ok)
+		}
+	}
+}
@@ -27,11 +27,20 @@ USE `SpinnrTechnology` ;
 CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`Level` (
     `ID` INT AUTO_INCREMENT PRIMARY KEY,
     `Name` VARCHAR(255) NOT NULL,
-    `LV` INT NOT NULL)
+    `LV` INT NOT NULL,
+    `XPThreshold` INT NOT NULL DEFAULT 0,
+    UNIQUE KEY `UQ_Level_LV` (`LV`))
 ENGINE = InnoDB
 DEFAULT CHARACTER SET = utf8mb4
 COLLATE = utf8mb4_0900_ai_ci;
 
+-- -----------------------------------------------------
+-- Insert the starting level, players register at it before any level is managed
+-- -----------------------------------------------------
+INSERT INTO `SpinnrTechnology`.`Level` (Name, LV, XPThreshold)
+SELECT 'Beginner', 1, 0
+WHERE NOT EXISTS (SELECT 1 FROM `SpinnrTechnology`.`Level` WHERE LV = 1);
+
 
 -- -----------------------------------------------------
 -- Table `SpinnrTechnology`.`Player`
@@ -40,6 +49,16 @@ CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`Player` (
     `ID` INT AUTO_INCREMENT PRIMARY KEY,
     `Name` VARCHAR(255) NOT NULL,
     `LevelID` INT NOT NULL,
+    `XP` INT NOT NULL DEFAULT 0,
+    `DisplayName` VARCHAR(64) NULL DEFAULT NULL,
+    `AvatarURL` VARCHAR(2048) NULL DEFAULT NULL,
+    `Country` CHAR(2) NULL DEFAULT NULL,
+    `Locale` VARCHAR(35) NULL DEFAULT NULL,
+    `CreatedAt` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `UpdatedAt` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `Version` INT NOT NULL DEFAULT 1,
+    `DeletedAt` DATETIME NULL DEFAULT NULL,
+    FULLTEXT KEY `FT_Player_Name` (`Name`) WITH PARSER ngram,
     FOREIGN KEY (`LevelID`) REFERENCES `Level`(`ID`)
 )
 ENGINE = InnoDB
@@ -47,13 +66,142 @@ DEFAULT CHARACTER SET = utf8mb4
 COLLATE = utf8mb4_0900_ai_ci;
 
 
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`PlayerAudit`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`PlayerAudit` (
+    `ID` BIGINT AUTO_INCREMENT PRIMARY KEY,
+    `PlayerID` INT NOT NULL,
+    `Actor` VARCHAR(255) NOT NULL,
+    `Field` VARCHAR(64) NOT NULL,
+    `OldValue` TEXT NOT NULL,
+    `NewValue` TEXT NOT NULL,
+    `ChangedAt` DATETIME NOT NULL,
+    INDEX `IX_PlayerAudit_PlayerID` (`PlayerID`),
+    FOREIGN KEY (`PlayerID`) REFERENCES `Player`(`ID`))
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`PlayerCredential`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`PlayerCredential` (
+    `PlayerID` INT PRIMARY KEY,
+    `Username` VARCHAR(64) NOT NULL,
+    `PasswordHash` VARCHAR(255) NOT NULL,
+    `CreatedAt` DATETIME NOT NULL,
+    UNIQUE KEY `UQ_PlayerCredential_Username` (`Username`),
+    FOREIGN KEY (`PlayerID`) REFERENCES `Player`(`ID`))
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`RefreshToken`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`RefreshToken` (
+    `ID` BIGINT AUTO_INCREMENT PRIMARY KEY,
+    `PlayerID` INT NOT NULL,
+    `FamilyID` CHAR(32) NOT NULL,
+    `TokenHash` CHAR(64) NOT NULL,
+    `ExpiresAt` DATETIME NOT NULL,
+    `RevokedAt` DATETIME NULL DEFAULT NULL,
+    `CreatedAt` DATETIME NOT NULL,
+    UNIQUE KEY `UQ_RefreshToken_TokenHash` (`TokenHash`),
+    INDEX `IX_RefreshToken_FamilyID` (`FamilyID`),
+    FOREIGN KEY (`PlayerID`) REFERENCES `Player`(`ID`))
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`PlayerRole`
+-- Every account is a player, the rows grant support, game-master, finance or admin.
+-- Grant the first admin by hand: INSERT INTO PlayerRole (PlayerID, Role) VALUES (<id>, 'admin');
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`PlayerRole` (
+    `PlayerID` INT NOT NULL,
+    `Role` VARCHAR(32) NOT NULL,
+    PRIMARY KEY (`PlayerID`, `Role`),
+    FOREIGN KEY (`PlayerID`) REFERENCES `Player`(`ID`))
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`APIKey`
+-- Keys of the service-to-service calls, every service verifies X-API-Key against it.
+-- Scopes are the granted permissions separated by spaces.
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`APIKey` (
+    `ID` BIGINT AUTO_INCREMENT PRIMARY KEY,
+    `Name` VARCHAR(64) NOT NULL,
+    `Prefix` VARCHAR(16) NOT NULL,
+    `KeyHash` CHAR(64) NOT NULL,
+    `Scopes` VARCHAR(1024) NOT NULL,
+    `RotatedFromID` BIGINT NULL DEFAULT NULL,
+    `CreatedAt` DATETIME NOT NULL,
+    `ExpiresAt` DATETIME NULL DEFAULT NULL,
+    `RevokedAt` DATETIME NULL DEFAULT NULL,
+    `LastUsedAt` DATETIME NULL DEFAULT NULL,
+    UNIQUE KEY `UQ_APIKey_KeyHash` (`KeyHash`),
+    FOREIGN KEY (`RotatedFromID`) REFERENCES `APIKey`(`ID`))
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`RateLimitBucket`
+-- Token buckets of the rate limits shared by every service with RATE_LIMIT_BACKEND=mysql.
+-- BucketKey is the policy name and the caller, such as auth:login:ip:10.0.0.1.
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`RateLimitBucket` (
+    `BucketKey` VARCHAR(191) NOT NULL PRIMARY KEY,
+    `Tokens` DOUBLE NOT NULL,
+    `UpdatedAt` DATETIME(6) NOT NULL)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`SchemaVersion`
+-- Versions of the schema applied to the database, /readyz of every service
+-- fails until the latest version is at least the one its queries are written for.
+-- Version 1 is the schema of the migrations applied before this table.
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`SchemaVersion` (
+    `Version` INT NOT NULL PRIMARY KEY,
+    `AppliedAt` DATETIME NOT NULL)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+INSERT IGNORE INTO `SpinnrTechnology`.`SchemaVersion` (`Version`, `AppliedAt`) VALUES (1, UTC_TIMESTAMP());
+
+
 -- -----------------------------------------------------
 -- Table `SpinnrTechnology`.`PrizePool`
 -- -----------------------------------------------------
 CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`Reservation` (
     `ID` INT AUTO_INCREMENT PRIMARY KEY,
     `RoomID` INT NOT NULL,
-    `Date` DATETIME NOT NULL,
+    -- Time slot of the reservation in UTC, the slots of a room do not overlap
+    `StartTime` DATETIME NOT NULL,
+    `EndTime` DATETIME NOT NULL,
+    -- pending, confirmed, checked_in, completed, cancelled or no_show
+    `Status` VARCHAR(16) NOT NULL DEFAULT 'pending',
+    -- Player who made the reservation, NULL when made with an API key
+    `PlayerID` INT NULL DEFAULT NULL,
+    `CheckedInAt` DATETIME NULL DEFAULT NULL,
+    INDEX `IX_Reservation_RoomID_StartTime` (`RoomID`, `StartTime`),
+    INDEX `IX_Reservation_Status_StartTime` (`Status`, `StartTime`),
     FOREIGN KEY (`RoomID`) REFERENCES `Room`(`ID`))
 ENGINE = InnoDB
 DEFAULT CHARACTER SET = utf8mb4
@@ -68,11 +216,92 @@ CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`Room` (
     `Name` VARCHAR(255) NOT NULL,
     `Status` INT NOT NULL,
     `Description` TEXT NOT NULL,
-    `PlayerIDs` TEXT NOT NULL)
+    -- Only read by the migration to RoomPlayer, it keeps the entries that could not be moved
+    `PlayerIDs` TEXT NOT NULL,
+    -- Join rules, a Capacity of 0 has no limit and MinLV and MaxLV are checked when set
+    `Capacity` INT NOT NULL DEFAULT 0,
+    `MinPlayers` INT NOT NULL DEFAULT 0,
+    `MinLV` INT NULL DEFAULT NULL,
+    `MaxLV` INT NULL DEFAULT NULL,
+    -- Private rooms are joined with the invite code or the password
+    `Private` BOOLEAN NOT NULL DEFAULT FALSE,
+    `InviteCode` VARCHAR(32) NULL DEFAULT NULL,
+    `PasswordHash` VARCHAR(255) NULL DEFAULT NULL)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`RoomPlayer`
+-- Players in the rooms, replacing the comma separated Room.PlayerIDs. Existing
+-- PlayerIDs are moved here by gameRoomManagementSystem -migrate-room-players.
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`RoomPlayer` (
+    `RoomID` INT NOT NULL,
+    `PlayerID` INT NOT NULL,
+    `JoinedAt` DATETIME NOT NULL,
+    PRIMARY KEY (`RoomID`, `PlayerID`),
+    INDEX `IX_RoomPlayer_PlayerID` (`PlayerID`),
+    FOREIGN KEY (`RoomID`) REFERENCES `Room`(`ID`) ON DELETE CASCADE,
+    FOREIGN KEY (`PlayerID`) REFERENCES `Player`(`ID`) ON DELETE CASCADE)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+-- Version 2 adds RoomPlayer
+INSERT IGNORE INTO `SpinnrTechnology`.`SchemaVersion` (`Version`, `AppliedAt`) VALUES (2, UTC_TIMESTAMP());
+-- Version 3 adds the join rules of the rooms, see roomRules.sql of gameRoomManagementSystem
+INSERT IGNORE INTO `SpinnrTechnology`.`SchemaVersion` (`Version`, `AppliedAt`) VALUES (3, UTC_TIMESTAMP());
+
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`RoomStatusHistory`
+-- Transitions of the status of the rooms, made with POST /rooms/:id/transitions
+-- of gameRoomManagementSystem.
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`RoomStatusHistory` (
+    `ID` BIGINT AUTO_INCREMENT PRIMARY KEY,
+    `RoomID` INT NOT NULL,
+    `Event` VARCHAR(32) NOT NULL,
+    `FromStatus` INT NOT NULL,
+    `ToStatus` INT NOT NULL,
+    `Actor` VARCHAR(255) NOT NULL,
+    `Reason` VARCHAR(255) NOT NULL,
+    `CreatedAt` DATETIME NOT NULL,
+    INDEX `IX_RoomStatusHistory_RoomID` (`RoomID`, `ID`),
+    FOREIGN KEY (`RoomID`) REFERENCES `Room`(`ID`) ON DELETE CASCADE)
 ENGINE = InnoDB
 DEFAULT CHARACTER SET = utf8mb4
 COLLATE = utf8mb4_0900_ai_ci;
 
+-- Version 4 adds RoomStatusHistory
+INSERT IGNORE INTO `SpinnrTechnology`.`SchemaVersion` (`Version`, `AppliedAt`) VALUES (4, UTC_TIMESTAMP());
+-- Version 5 replaces the Date of the reservations with their time slot, see reservationSlot.sql of gameRoomManagementSystem
+INSERT IGNORE INTO `SpinnrTechnology`.`SchemaVersion` (`Version`, `AppliedAt`) VALUES (5, UTC_TIMESTAMP());
+-- Version 6 adds the status of the reservations, see reservationStatus.sql of gameRoomManagementSystem
+INSERT IGNORE INTO `SpinnrTechnology`.`SchemaVersion` (`Version`, `AppliedAt`) VALUES (6, UTC_TIMESTAMP());
+
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`ReservationPlayer`
+-- Players of the reservations, they are only in the room for the time slot of
+-- the reservation so they are kept apart from RoomPlayer.
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`ReservationPlayer` (
+    `ReservationID` INT NOT NULL,
+    `PlayerID` INT NOT NULL,
+    PRIMARY KEY (`ReservationID`, `PlayerID`),
+    INDEX `IX_ReservationPlayer_PlayerID` (`PlayerID`),
+    FOREIGN KEY (`ReservationID`) REFERENCES `Reservation`(`ID`) ON DELETE CASCADE,
+    FOREIGN KEY (`PlayerID`) REFERENCES `Player`(`ID`) ON DELETE CASCADE)
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+-- Version 7 adds ReservationPlayer
+INSERT IGNORE INTO `SpinnrTechnology`.`SchemaVersion` (`Version`, `AppliedAt`) VALUES (7, UTC_TIMESTAMP());
+
 
 -- -----------------------------------------------------
 -- Table `SpinnrTechnology`.`GameLog`
@@ -93,10 +322,12 @@ COLLATE = utf8mb4_0900_ai_ci;
 -- -----------------------------------------------------
 CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`Payment` (
     `ID` INT AUTO_INCREMENT PRIMARY KEY,
+    `PlayerID` INT NULL DEFAULT NULL,
     `Method` VARCHAR(255) NOT NULL,
     `Amount` FLOAT NOT NULL,
     `Describle` TEXT NOT NULL,
-    `Timestamp` DATETIME NOT NULL)
+    `Timestamp` DATETIME NOT NULL,
+    INDEX `IX_Payment_PlayerID` (`PlayerID`))
 ENGINE = InnoDB
 DEFAULT CHARACTER SET = utf8mb4
 COLLATE = utf8mb4_0900_ai_ci;
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"io"
+
+	shared "github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/config"
+)
+
+// Config is the configuration of paymentProcessingSystem.
+type Config struct {
+	Port      string           `config:"port" env:"PORT" default:":8082"`
+	Database  shared.Database  `config:"database"`
+	Auth      Auth             `config:"auth"`
+	RateLimit shared.RateLimit `config:"rate_limit"`
+	Shutdown  shared.Shutdown  `config:"shutdown"`
+	Health    shared.Health    `config:"health"`
+	Tracing   shared.Tracing   `config:"tracing"`
+	Log       shared.Log       `config:"log"`
+}
+
+// Auth verifies the access tokens issued by playerManagementSystem.
+type Auth struct {
+	JWTSecret shared.Secret `config:"jwt_secret" env:"JWT_SECRET"`
+	JWKSFile  string        `config:"jwks_file" env:"JWT_JWKS_FILE"`
+}
+
+func (c *Config) Validate() error {
+	if err := c.Database.Validate(); err != nil {
+		return err
+	}
+	if c.Auth.JWTSecret == "" && c.Auth.JWKSFile == "" {
+		return fmt.Errorf("JWT_SECRET or JWT_JWKS_FILE is required")
+	}
+	if c.Auth.JWTSecret.Placeholder() {
+		return fmt.Errorf("JWT_SECRET is a placeholder, set it to the secret of playerManagementSystem")
+	}
+	if err := c.RateLimit.Validate(); err != nil {
+		return err
+	}
+	if err := c.Shutdown.Validate(); err != nil {
+		return err
+	}
+	if err := c.Health.Validate(); err != nil {
+		return err
+	}
+	if err := c.Tracing.Validate(); err != nil {
+		return err
+	}
+	return c.Log.Validate()
+}
+
+// Load fills cfg from the environment, the .env file, the YAML or TOML file at
+// path and the defaults, then validates it, see the config package of shared.
+func Load(cfg *Config, path string) error {
+	return shared.Load(cfg, path)
+}
+
+// Print writes cfg with its secrets redacted.
+func Print(w io.Writer, cfg *Config) error {
+	return shared.Print(w, cfg)
+}
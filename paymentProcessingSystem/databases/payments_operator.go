@@ -1,23 +1,30 @@
 package databases
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/paymentProcessingSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
 )
 
-func GetPayment(db *sql.DB, id int) (*models.Payment, error) {
+func GetPayment(ctx context.Context, db *sql.DB, id int) (*models.Payment, error) {
+	ctx, span := tracing.Start(ctx, "databases.GetPayment")
+	defer span.End()
+
 	var payment models.Payment
 	var describleString string
-	err := db.QueryRow(`
+	var playerID sql.NullInt64
+	err := db.QueryRowContext(ctx, `
 		SELECT 
-		ID, Method, Amount, Describle, Timestamp 
+		ID, PlayerID, Method, Amount, Describle, Timestamp 
 		FROM Payment 
 		WHERE ID = ?
 	`, id).Scan(
 		&payment.ID,
+		&playerID,
 		&payment.Method,
 		&payment.Amount,
 		&describleString,
@@ -28,6 +35,8 @@ func GetPayment(db *sql.DB, id int) (*models.Payment, error) {
 	} else if err != nil {
 		return nil, fmt.Errorf("error scanning row with GetPayment: %w", err)
 	}
+	// Payments made before they were linked to a player have no PlayerID
+	payment.PlayerID = int(playerID.Int64)
 
 	err = json.Unmarshal([]byte(describleString), &payment.Describle)
 	if err != nil {
@@ -37,15 +46,18 @@ func GetPayment(db *sql.DB, id int) (*models.Payment, error) {
 	return &payment, err
 }
 
-func AddPayment(db *sql.DB, payment models.Payment) (int, error) {
+func AddPayment(ctx context.Context, db *sql.DB, payment models.Payment) (int, error) {
+	ctx, span := tracing.Start(ctx, "databases.AddPayment")
+	defer span.End()
+
 	jsonBytes, err := json.Marshal(payment.Describle)
 	if err != nil {
 		return 0, fmt.Errorf("error marshal on AddPayment: %w", err)
 	}
-	result, err := db.Exec(`
-		INSERT INTO Payment (Method, Amount, Describle, Timestamp) 
-		VALUES (?, ?, ?, Now())
-	`, payment.Method, payment.Amount, jsonBytes)
+	result, err := db.ExecContext(ctx, `
+		INSERT INTO Payment (PlayerID, Method, Amount, Describle, Timestamp) 
+		VALUES (?, ?, ?, ?, Now())
+	`, payment.PlayerID, payment.Method, payment.Amount, jsonBytes)
 	if err != nil {
 		return 0, fmt.Errorf("error querying database with AddPayment: %w", err)
 	}
@@ -25,10 +25,12 @@ USE `SpinnrTechnology` ;
 -- -----------------------------------------------------
 CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`Payment` (
     `ID` INT AUTO_INCREMENT PRIMARY KEY,
+    `PlayerID` INT NULL DEFAULT NULL,
     `Method` VARCHAR(255) NOT NULL,
     `Amount` FLOAT NOT NULL,
     `Describle` TEXT NOT NULL,
-    `Timestamp` DATETIME NOT NULL)
+    `Timestamp` DATETIME NOT NULL,
+    INDEX `IX_Payment_PlayerID` (`PlayerID`))
 ENGINE = InnoDB
 DEFAULT CHARACTER SET = utf8mb4
 COLLATE = utf8mb4_0900_ai_ci;
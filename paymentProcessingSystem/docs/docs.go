@@ -9,6 +9,7 @@ const docTemplate = `{
     "info": {
         "description": "{{escape .Description}}",
         "title": "{{.Title}}",
+        "termsOfService": "http://swagger.io/terms/",
         "contact": {
             "name": "Steven Poon",
             "url": "https://github.com/RYANCOAL9999",
@@ -23,9 +24,37 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/healthz": {
+            "get": {
+                "description": "Reports that the process is alive, it does not check any dependency.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness",
+                "responses": {
+                    "200": {
+                        "description": "The process is alive",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
         "/payments": {
             "post": {
-                "description": "Create a new payment entry in the database using the provided payment details. The payment can be of various methods including credit card, bank transfer, third-party, or blockchain.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Create a new payment entry in the database using the provided payment details. The payment can be of various methods including credit card, bank transfer, third-party, or blockchain: CreditCardPayment, BankTransfer, ThirdPartyPayment or BlockchainPayment. The payment is only recorded once its provider accepted it. Players can only pay for themselves, paying for others needs the payments:create permission.",
                 "consumes": [
                     "application/json"
                 ],
@@ -55,7 +84,25 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Bad request due to invalid input",
+                        "description": "Bad request due to invalid input or unknown method",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Payment for another player without permission payments:create",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -65,13 +112,27 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
+                    },
+                    "502": {
+                        "description": "Payment provider failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
         "/payments/{id}": {
             "get": {
-                "description": "Get details of a specific payment identified by its ID from the database.",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Get details of a specific payment identified by its ID from the database. Players can read their own payments, other payments need the payments:read permission.",
                 "consumes": [
                     "application/json"
                 ],
@@ -104,6 +165,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Payment of another player without permission payments:read",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Payment not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -112,9 +191,107 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Runs the readiness checks of the service, such as the database, the schema version and the services it depends on, with the status and latency of each.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness",
+                "responses": {
+                    "200": {
+                        "description": "Every check passed",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "A check failed or the service is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "Git commit and build time of the binary and the Go version it was built with.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Build information",
+                "responses": {
+                    "200": {
+                        "description": "Build information",
+                        "schema": {
+                            "$ref": "#/definitions/health.Version"
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
+        "health.CheckResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "number",
+                    "example": 1.25
+                },
+                "name": {
+                    "type": "string",
+                    "example": "database"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/health.CheckResult"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "health.Version": {
+            "type": "object",
+            "properties": {
+                "build_time": {
+                    "type": "string",
+                    "example": "2024-07-27T16:09:21Z"
+                },
+                "commit": {
+                    "type": "string",
+                    "example": "9fd67d9"
+                },
+                "go_version": {
+                    "type": "string",
+                    "example": "go1.20.14"
+                }
+            }
+        },
         "models.Describle": {
             "type": "object",
             "properties": {
@@ -146,6 +323,9 @@ const docTemplate = `{
             "properties": {
                 "error": {
                     "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
                 }
             }
         },
@@ -169,6 +349,9 @@ const docTemplate = `{
                 "method": {
                     "type": "string"
                 },
+                "player_id": {
+                    "type": "integer"
+                },
                 "timestamp": {
                     "type": "string"
                 }
@@ -185,6 +368,20 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key of another service from POST /api_keys of playerManagementSystem",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "Access token from POST /auth/login of playerManagementSystem, as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -194,7 +391,7 @@ var SwaggerInfo = &swag.Spec{
 	Host:             ":8082",
 	BasePath:         "/v2",
 	Schemes:          []string{},
-	Title:            "Payment Processing System  API",
+	Title:            "Payment Processing System API",
 	Description:      "This is a payment processing system server.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
@@ -2,15 +2,34 @@ package external
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/paymentProcessingSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/logging"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
 )
 
-func MakePayment(paymentReq interface{}, url string) (*models.PaymentResponse, error) {
+// client sends the requests to the payment providers, traced and carrying the
+// trace context of the payment in the traceparent header.
+var client = &http.Client{
+	Timeout:   10 * time.Second,
+	Transport: tracing.Transport(http.DefaultTransport),
+}
+
+// setRequestID sends the ID of the request making the payment to the provider,
+// so its logs can be matched with ours.
+func setRequestID(ctx context.Context, req *http.Request) {
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set(middleware.RequestIDHeader, id)
+	}
+}
+
+func MakePayment(ctx context.Context, paymentReq interface{}, url string) (*models.PaymentResponse, error) {
 
 	// Convert the transfer request to JSON
 	requestBody, err := json.Marshal(paymentReq)
@@ -19,14 +38,14 @@ func MakePayment(paymentReq interface{}, url string) (*models.PaymentResponse, e
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new request: %v", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	setRequestID(ctx, req)
 
 	//Send HTTP request
-	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %v", err)
@@ -47,17 +66,17 @@ func MakePayment(paymentReq interface{}, url string) (*models.PaymentResponse, e
 	return &transferResp, nil
 }
 
-func CheckPaymentStatus(transactionID string, url string) (*models.PaymentResponse, error) {
+func CheckPaymentStatus(ctx context.Context, transactionID string, url string) (*models.PaymentResponse, error) {
 	statusURL := fmt.Sprintf("%s/status/%s", url, transactionID)
 
 	// Create HTTP request
-	req, err := http.NewRequest("GET", statusURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", statusURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new request: %v", err)
 	}
+	setRequestID(ctx, req)
 
 	// Send HTTP request
-	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %v", err)
@@ -3,11 +3,19 @@ package handlers
 import (
 	"database/sql"
 
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/paymentProcessingSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupPaymentsRoutes(payments *gin.RouterGroup, db *sql.DB) {
+// errorResponse is the body of a failed request, carrying its ID to find its logs.
+func errorResponse(c *gin.Context, message string) models.ErrorResponse {
+	return models.ErrorResponse{Error: message, RequestID: middleware.RequestID(c)}
+}
+
+func SetupPaymentsRoutes(payments *gin.RouterGroup, db *sql.DB, limiter middleware.RateLimitStore) {
 	// Player routes
-	payments.GET("/:id", func(c *gin.Context) { ShowPayment(c, db) })
-	payments.POST("/", func(c *gin.Context) { CreatePayment(c, db) })
+	payments.Use(middleware.RateLimit(limiter, DefaultRateLimit))
+	payments.GET("/:id", middleware.RequireAuth(), func(c *gin.Context) { ShowPayment(c, db) })
+	payments.POST("/", middleware.RequireAuth(), middleware.RateLimit(limiter, CreatePaymentRateLimit), func(c *gin.Context) { CreatePayment(c, db) })
 }
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Domain metrics of the payments routes.
+var (
+	payments = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "payments_total",
+		Help:      "Payments created by method and status.",
+	}, []string{"method", "status"})
+)
+
+func init() {
+	prometheus.MustRegister(payments)
+}
@@ -1,18 +1,21 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/paymentProcessingSystem/databases"
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/paymentProcessingSystem/external"
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/paymentProcessingSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
 	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
 )
 
-func MakeCreditCardPayment(payment models.Payment) *models.PaymentResponse {
+func MakeCreditCardPayment(ctx context.Context, payment models.Payment) *models.PaymentResponse {
 
 	var url string = "https://api.paymentgateway.com/payment"
 
@@ -25,15 +28,15 @@ func MakeCreditCardPayment(payment models.Payment) *models.PaymentResponse {
 	}
 
 	// Create payment request
-	paymentResp, err := external.MakePayment(paymentReq, url)
+	paymentResp, err := external.MakePayment(ctx, paymentReq, url)
 	if err != nil {
-		fmt.Printf("Payment failed: %v\n", err)
+		slog.ErrorContext(ctx, "payment failed", "url", url, "error", err)
 		return nil
 	}
 	return paymentResp
 }
 
-func MakeBankTransfer(payment models.Payment) *models.PaymentResponse {
+func MakeBankTransfer(ctx context.Context, payment models.Payment) *models.PaymentResponse {
 
 	const url string = "https://api.bank.com/transfer"
 
@@ -46,15 +49,15 @@ func MakeBankTransfer(payment models.Payment) *models.PaymentResponse {
 	}
 
 	// Create payment request
-	paymentResp, err := external.MakePayment(paymentReq, url)
+	paymentResp, err := external.MakePayment(ctx, paymentReq, url)
 	if err != nil {
-		fmt.Printf("Payment failed: %v\n", err)
+		slog.ErrorContext(ctx, "payment failed", "url", url, "error", err)
 		return nil
 	}
 	return paymentResp
 }
 
-func MakeThirdPartyPayment(payment models.Payment) *models.PaymentResponse {
+func MakeThirdPartyPayment(ctx context.Context, payment models.Payment) *models.PaymentResponse {
 
 	const url string = "https://api.thirdParty.com/payment"
 
@@ -68,24 +71,24 @@ func MakeThirdPartyPayment(payment models.Payment) *models.PaymentResponse {
 	}
 
 	// Create payment request
-	paymentResp, err := external.MakePayment(paymentReq, url)
+	paymentResp, err := external.MakePayment(ctx, paymentReq, url)
 	if err != nil {
-		fmt.Printf("Payment failed: %v\n", err)
+		slog.ErrorContext(ctx, "payment failed", "url", url, "error", err)
 		return nil
 	}
 
-	fmt.Printf("Payment successful: %+v\n", paymentResp)
+	slog.InfoContext(ctx, "payment made", "url", url, "transaction_id", paymentResp.TransactionID, "status", paymentResp.Status)
 
 	// Query payment status
-	statusResp, err := external.CheckPaymentStatus(paymentResp.TransactionID, url)
+	statusResp, err := external.CheckPaymentStatus(ctx, paymentResp.TransactionID, url)
 	if err != nil {
-		fmt.Printf("Check payment status failed: %v\n", err)
+		slog.ErrorContext(ctx, "checking the payment status failed", "url", url, "transaction_id", paymentResp.TransactionID, "error", err)
 		return nil
 	}
 	return statusResp
 }
 
-func MakeBlockchainPayment(payment models.Payment) *models.PaymentResponse {
+func MakeBlockchainPayment(ctx context.Context, payment models.Payment) *models.PaymentResponse {
 
 	const url string = "https://api.blockchainplatform.com/transaction"
 
@@ -98,57 +101,85 @@ func MakeBlockchainPayment(payment models.Payment) *models.PaymentResponse {
 	}
 
 	// Create payment request
-	paymentResp, err := external.MakePayment(paymentReq, url)
+	paymentResp, err := external.MakePayment(ctx, paymentReq, url)
 	if err != nil {
-		fmt.Printf("Blockchain payment failed: %v\n", err)
+		slog.ErrorContext(ctx, "blockchain payment failed", "url", url, "error", err)
 		return nil
 	}
 
-	fmt.Printf("Blockchain payment successful: %+v\n", paymentResp)
+	slog.InfoContext(ctx, "blockchain payment made", "url", url, "transaction_id", paymentResp.TransactionID, "status", paymentResp.Status)
 
 	// check payment status
-	statusResp, err := external.CheckPaymentStatus(paymentResp.TransactionID, url)
+	statusResp, err := external.CheckPaymentStatus(ctx, paymentResp.TransactionID, url)
 	if err != nil {
-		fmt.Printf("Check blockchain payment status failed: %v\n", err)
+		slog.ErrorContext(ctx, "checking the blockchain payment status failed", "url", url, "transaction_id", paymentResp.TransactionID, "error", err)
 		return nil
 	}
 	return statusResp
 }
 
 // @Summary      Retrieve a payment by ID
-// @Description  Get details of a specific payment identified by its ID from the database.
+// @Description  Get details of a specific payment identified by its ID from the database. Players can read their own payments, other payments need the payments:read permission.
 // @Tags         payments
 // @Accept       json
 // @Produce      json
 // @Param        id  path  int  true  "Payment ID"
 // @Success      200  {object}  models.Payment  "Payment details"
 // @Failure      400  {object}  models.ErrorResponse  "Invalid ID supplied"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Payment of another player without permission payments:read"
+// @Failure      404  {object}  models.ErrorResponse  "Payment not found"
 // @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /payments/{id} [get]
 func ShowPayment(c *gin.Context, db *sql.DB) {
-	id, _ := strconv.Atoi(c.Param("id"))
-	payment, err := databases.GetPayment(db, id)
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid payment id"))
+		return
+	}
+	payment, err := databases.GetPayment(c.Request.Context(), db, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	// Players can only read their own payments
+	if !middleware.IsPlayer(c, payment.PlayerID) && !Policy.Allows(c, PermPaymentsRead) {
+		c.JSON(http.StatusForbidden, errorResponse(c, "missing permission "+PermPaymentsRead+" to read another player's payment"))
 		return
 	}
 	c.JSON(http.StatusOK, payment)
 }
 
 // @Summary      Create a new payment
-// @Description  Create a new payment entry in the database using the provided payment details. The payment can be of various methods including credit card, bank transfer, third-party, or blockchain.
+// @Description  Create a new payment entry in the database using the provided payment details. The payment can be of various methods including credit card, bank transfer, third-party, or blockchain: CreditCardPayment, BankTransfer, ThirdPartyPayment or BlockchainPayment. The payment is only recorded once its provider accepted it. Players can only pay for themselves, paying for others needs the payments:create permission.
 // @Tags         payments
 // @Accept       json
 // @Produce      json
 // @Param        payment  body  models.Payment  true  "Payment details to be created"
 // @Success      201  {object}  models.PaymentResult  "Payment created successfully with the payment ID"
-// @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid input"
+// @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid input or unknown method"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Payment for another player without permission payments:create"
+// @Failure      429  {object}  models.ErrorResponse  "Rate limit exceeded"
 // @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Failure      502  {object}  models.ErrorResponse  "Payment provider failed"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /payments [post]
 func CreatePayment(c *gin.Context, db *sql.DB) {
 	var payment models.Payment
 	if err := c.BindJSON(&payment); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+	// Players can only pay for themselves
+	if !middleware.IsPlayer(c, payment.PlayerID) && !Policy.Allows(c, PermPaymentsCreate) {
+		c.JSON(http.StatusForbidden, errorResponse(c, "missing permission "+PermPaymentsCreate+" to pay for other players"))
 		return
 	}
 
@@ -156,21 +187,31 @@ func CreatePayment(c *gin.Context, db *sql.DB) {
 
 	switch method := payment.Method; method {
 	case "CreditCardPayment":
-		item = MakeCreditCardPayment(payment)
+		item = MakeCreditCardPayment(c.Request.Context(), payment)
 	case "BankTransfer":
-		item = MakeBankTransfer(payment)
+		item = MakeBankTransfer(c.Request.Context(), payment)
 	case "ThirdPartyPayment":
-		item = MakeThirdPartyPayment(payment)
+		item = MakeThirdPartyPayment(c.Request.Context(), payment)
 	case "BlockchainPayment":
-		item = MakeBlockchainPayment(payment)
+		item = MakeBlockchainPayment(c.Request.Context(), payment)
+	default:
+		c.JSON(http.StatusBadRequest, errorResponse(c, "unknown payment method "+strconv.Quote(method)))
+		return
+	}
+	// The payment is only recorded once the provider answered
+	if item == nil {
+		c.JSON(http.StatusBadGateway, errorResponse(c, "payment provider failed for "+payment.Method))
+		return
 	}
 
-	paymentID, err := databases.AddPayment(db, payment)
+	paymentID, err := databases.AddPayment(c.Request.Context(), db, payment)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
 		return
 	}
 
+	payments.WithLabelValues(payment.Method, item.Status).Inc()
+
 	var result models.PaymentResult
 	result.ID = paymentID
 	result.Status = item.Status
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
+)
+
+// Permissions of the payments routes.
+const (
+	PermPaymentsRead   = "payments:read"
+	PermPaymentsCreate = "payments:create"
+)
+
+// Policy grants the permissions to roles, players can always read and create their own payments.
+var Policy = middleware.Policy{
+	PermPaymentsRead:   {middleware.RoleSupport, middleware.RoleFinance, middleware.RoleAdmin},
+	PermPaymentsCreate: {middleware.RoleFinance, middleware.RoleAdmin},
+}
+
+// Rate limits of the routes, creating payments has a stricter one on top of the default.
+var (
+	DefaultRateLimit       = middleware.RateLimitPolicy{Name: "payments", Limit: 120, Window: time.Minute}
+	CreatePaymentRateLimit = middleware.RateLimitPolicy{Name: "payments:create", Limit: 10, Window: time.Minute}
+)
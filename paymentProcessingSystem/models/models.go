@@ -12,9 +12,10 @@ type Describle struct {
 	Description    string `json:"description"`
 }
 
-// table for Payment
+// table for Payment, player_id is the authenticated player who made the payment
 type Payment struct {
 	ID        int       `json:"id"`
+	PlayerID  int       `json:"player_id"`
 	Method    string    `json:"method" binding:"required"`
 	Amount    float64   `json:"amount" binding:"required"`
 	Describle Describle `json:"describle" binding:"required"`
@@ -58,7 +59,9 @@ type PaymentResponse struct {
 	Message       string `json:"message"`
 }
 
-// ErrorResponse represents an error response with a single error message.
+// ErrorResponse represents an error response with a single error message and
+// the ID of the request, to find its logs.
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
 }
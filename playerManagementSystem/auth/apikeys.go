@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
+)
+
+// APIKeyPrefix starts every API key so leaked keys are easy to spot.
+const APIKeyPrefix = "spk_"
+
+// apiKeyPrefixLength is how much of a key is stored in clear to tell keys apart.
+const apiKeyPrefixLength = len(APIKeyPrefix) + 8
+
+// NewAPIKey returns a random API key for the caller, its prefix and the hash stored server-side.
+func NewAPIKey() (string, string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", fmt.Errorf("error generating api key: %w", err)
+	}
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:apiKeyPrefixLength], middleware.HashAPIKey(key), nil
+}
+
+// DefaultAPIKeyOverlap is how long a rotated API key keeps working next to the new one.
+const DefaultAPIKeyOverlap = 24 * time.Hour
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidCredentials is returned when a username or password does not match.
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// MaxPasswordBytes is the longest password bcrypt hashes, in bytes rather than characters.
+const MaxPasswordBytes = 72
+
+// dummyHash is compared when the username does not exist, so a login takes
+// the same time whether or not the account exists.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+
+// HashPassword returns the bcrypt hash of a password.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword compares a password with its bcrypt hash, an empty hash never matches.
+func CheckPassword(hash, password string) error {
+	if hash == "" {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Issuer is the iss claim of the access tokens signed by this service.
+const Issuer = "playerManagementSystem"
+
+// Default lifetimes of the tokens.
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// Tokens signs access tokens and creates refresh tokens.
+type Tokens struct {
+	secret     []byte
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+func NewTokens(secret []byte, accessTTL, refreshTTL time.Duration) *Tokens {
+	return &Tokens{
+		secret:     secret,
+		AccessTTL:  accessTTL,
+		RefreshTTL: refreshTTL,
+	}
+}
+
+// AccessToken returns an HS256 signed JWT whose sub claim is the player ID
+// and roles claim the roles of the player.
+func (t *Tokens) AccessToken(playerID int, roles []string, now time.Time) (string, error) {
+	claims := middleware.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    Issuer,
+			Subject:   strconv.Itoa(playerID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.AccessTTL)),
+		},
+		Roles: roles,
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.secret)
+	if err != nil {
+		return "", fmt.Errorf("error signing access token: %w", err)
+	}
+	return signed, nil
+}
+
+// NewRefreshToken returns a random refresh token for the client and the hash stored server-side.
+func NewRefreshToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("error generating refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken is the SHA-256 of a refresh token, only hashes are stored
+// so a leaked table cannot be used to refresh sessions.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewFamilyID returns the ID shared by a refresh token and all the tokens it is rotated into.
+func NewFamilyID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating refresh token family: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
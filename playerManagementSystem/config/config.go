@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	shared "github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/config"
+)
+
+// Config is the configuration of playerManagementSystem.
+type Config struct {
+	Port          string           `config:"port" env:"PORT" default:":8081"`
+	StorageDriver string           `config:"storage_driver" env:"STORAGE_DRIVER" default:"mysql"`
+	Database      shared.Database  `config:"database"`
+	Auth          Auth             `config:"auth"`
+	RateLimit     shared.RateLimit `config:"rate_limit"`
+	Shutdown      shared.Shutdown  `config:"shutdown"`
+	Health        shared.Health    `config:"health"`
+	Tracing       shared.Tracing   `config:"tracing"`
+	Log           shared.Log       `config:"log"`
+}
+
+// Auth signs the access tokens the other services verify with the same secret.
+type Auth struct {
+	JWTSecret       shared.Secret `config:"jwt_secret" env:"JWT_SECRET" required:"true"`
+	JWKSFile        string        `config:"jwks_file" env:"JWT_JWKS_FILE"`
+	AccessTokenTTL  time.Duration `config:"access_token_ttl" env:"ACCESS_TOKEN_TTL" default:"15m"`
+	RefreshTokenTTL time.Duration `config:"refresh_token_ttl" env:"REFRESH_TOKEN_TTL" default:"720h"`
+}
+
+func (c *Config) Validate() error {
+	switch c.StorageDriver {
+	case "mysql":
+		if err := c.Database.Validate(); err != nil {
+			return err
+		}
+	case "memory":
+		if c.RateLimit.Backend == "mysql" {
+			return fmt.Errorf("RATE_LIMIT_BACKEND mysql needs STORAGE_DRIVER mysql")
+		}
+	default:
+		return fmt.Errorf("unknown STORAGE_DRIVER %q, use mysql or memory", c.StorageDriver)
+	}
+	if c.Auth.JWTSecret.Placeholder() {
+		return fmt.Errorf("JWT_SECRET is a placeholder, set it to a long random secret")
+	}
+	if c.Auth.AccessTokenTTL <= 0 || c.Auth.RefreshTokenTTL <= 0 {
+		return fmt.Errorf("ACCESS_TOKEN_TTL and REFRESH_TOKEN_TTL must be positive")
+	}
+	if err := c.RateLimit.Validate(); err != nil {
+		return err
+	}
+	if err := c.Shutdown.Validate(); err != nil {
+		return err
+	}
+	if err := c.Health.Validate(); err != nil {
+		return err
+	}
+	if err := c.Tracing.Validate(); err != nil {
+		return err
+	}
+	return c.Log.Validate()
+}
+
+// Load fills cfg from the environment, the .env file, the YAML or TOML file at
+// path and the defaults, then validates it, see the config package of shared.
+func Load(cfg *Config, path string) error {
+	return shared.Load(cfg, path)
+}
+
+// Print writes cfg with its secrets redacted.
+func Print(w io.Writer, cfg *Config) error {
+	return shared.Print(w, cfg)
+}
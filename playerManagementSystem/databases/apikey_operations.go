@@ -0,0 +1,219 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
+)
+
+const apiKeyColumns = "ID, Name, Prefix, Scopes, RotatedFromID, CreatedAt, ExpiresAt, RevokedAt, LastUsedAt"
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }, key *models.APIKey) error {
+	var scopes string
+	var rotatedFromID sql.NullInt64
+	var expiresAt, revokedAt, lastUsedAt sql.NullTime
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&scopes,
+		&rotatedFromID,
+		&key.CreatedAt,
+		&expiresAt,
+		&revokedAt,
+		&lastUsedAt,
+	)
+	if err != nil {
+		return err
+	}
+	key.Scopes = strings.Fields(scopes)
+	if rotatedFromID.Valid {
+		key.RotatedFromID = &rotatedFromID.Int64
+	}
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	return nil
+}
+
+// insertAPIKey stores the key and fills its ID, the scopes are stored space separated.
+func insertAPIKey(ctx context.Context, db execer, key *models.APIKey) error {
+	result, err := db.ExecContext(ctx, `
+		INSERT INTO APIKey (Name, Prefix, KeyHash, Scopes, RotatedFromID, CreatedAt, ExpiresAt) 
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, key.Name, key.Prefix, key.KeyHash, strings.Join(key.Scopes, " "), key.RotatedFromID, key.CreatedAt, key.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("error querying database with insertAPIKey: %w", err)
+	}
+	key.ID, _ = result.LastInsertId()
+	return nil
+}
+
+func CreateAPIKey(ctx context.Context, db *sql.DB, key *models.APIKey) error {
+	ctx, span := tracing.Start(ctx, "databases.CreateAPIKey")
+	defer span.End()
+
+	return insertAPIKey(ctx, db, key)
+}
+
+// ListAPIKeys returns every API key including the revoked ones, newest first.
+func ListAPIKeys(ctx context.Context, db *sql.DB) ([]models.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "databases.ListAPIKeys")
+	defer span.End()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT 
+		`+apiKeyColumns+` 
+		FROM APIKey 
+		ORDER BY ID DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying database with ListAPIKeys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var key models.APIKey
+		if err := scanAPIKey(rows, &key); err != nil {
+			return nil, fmt.Errorf("error scanning row with ListAPIKeys: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows with ListAPIKeys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey stops an API key from working, revoking it again keeps the first revocation time.
+func RevokeAPIKey(ctx context.Context, db *sql.DB, id int64) error {
+	ctx, span := tracing.Start(ctx, "databases.RevokeAPIKey")
+	defer span.End()
+
+	result, err := db.ExecContext(ctx, `
+		UPDATE APIKey 
+		SET RevokedAt = COALESCE(RevokedAt, ?) 
+		WHERE ID = ?
+	`, auditTime(), id)
+	if err != nil {
+		return fmt.Errorf("error querying database with RevokeAPIKey: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting affected rows with RevokeAPIKey: %w", err)
+	}
+	if rowsAffected == 0 {
+		// Nothing changes when the key is already revoked, tell it apart from a missing key
+		var exists int64
+		err = db.QueryRowContext(ctx, `
+			SELECT 
+			ID 
+			FROM APIKey 
+			WHERE ID = ?
+		`, id).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("error querying database with RevokeAPIKey: %w", err)
+		}
+	}
+	return nil
+}
+
+// RotateAPIKey replaces an active API key with next, which keeps its name and
+// scopes. The old key keeps working until overlapUntil, or its own expiry if
+// that comes first, so callers can switch over.
+func RotateAPIKey(ctx context.Context, db *sql.DB, id int64, next *models.APIKey, overlapUntil time.Time) error {
+	ctx, span := tracing.Start(ctx, "databases.RotateAPIKey")
+	defer span.End()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction with RotateAPIKey: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current models.APIKey
+	err = scanAPIKey(tx.QueryRowContext(ctx, `
+		SELECT 
+		`+apiKeyColumns+` 
+		FROM APIKey 
+		WHERE ID = ? 
+		FOR UPDATE
+	`, id), &current)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("error querying database with RotateAPIKey: %w", err)
+	} else if err != nil {
+		return fmt.Errorf("error scanning row with RotateAPIKey: %w", err)
+	}
+
+	if current.RevokedAt != nil || (current.ExpiresAt != nil && !current.ExpiresAt.After(next.CreatedAt)) {
+		return fmt.Errorf("error rotating api key %d: %w", id, ErrAPIKeyInactive)
+	}
+	if current.ExpiresAt == nil || overlapUntil.Before(*current.ExpiresAt) {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE APIKey 
+			SET ExpiresAt = ? 
+			WHERE ID = ?
+		`, overlapUntil, id)
+		if err != nil {
+			return fmt.Errorf("error querying database with RotateAPIKey: %w", err)
+		}
+	}
+
+	next.Name = current.Name
+	next.Scopes = current.Scopes
+	next.RotatedFromID = &current.ID
+	if err := insertAPIKey(ctx, tx, next); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction with RotateAPIKey: %w", err)
+	}
+	return nil
+}
+
+// VerifyAPIKey returns the ID and scopes of the active API key with the hash and records its use.
+func VerifyAPIKey(ctx context.Context, db *sql.DB, keyHash string) (int64, []string, error) {
+	ctx, span := tracing.Start(ctx, "databases.VerifyAPIKey")
+	defer span.End()
+
+	now := auditTime()
+	var id int64
+	var scopes string
+	err := db.QueryRowContext(ctx, `
+		SELECT 
+		ID, Scopes 
+		FROM APIKey 
+		WHERE KeyHash = ? AND RevokedAt IS NULL AND (ExpiresAt IS NULL OR ExpiresAt > ?)
+	`, keyHash, now).Scan(
+		&id,
+		&scopes,
+	)
+	if err == sql.ErrNoRows {
+		return 0, nil, fmt.Errorf("error querying database with VerifyAPIKey: %w", err)
+	} else if err != nil {
+		return 0, nil, fmt.Errorf("error scanning row with VerifyAPIKey: %w", err)
+	}
+
+	_, err = db.ExecContext(ctx, `
+		UPDATE APIKey 
+		SET LastUsedAt = ? 
+		WHERE ID = ?
+	`, now, id)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error querying database with VerifyAPIKey: %w", err)
+	}
+	return id, strings.Fields(scopes), nil
+}
@@ -0,0 +1,144 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
+)
+
+// insertPlayerAudits records the changed player fields in the transaction of the change.
+func insertPlayerAudits(ctx context.Context, tx *sql.Tx, audits []models.PlayerAudit) error {
+	for _, audit := range audits {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO PlayerAudit (PlayerID, Actor, Field, OldValue, NewValue, ChangedAt) 
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, audit.PlayerID, audit.Actor, audit.Field, audit.OldValue, audit.NewValue, audit.ChangedAt)
+		if err != nil {
+			return fmt.Errorf("error querying database with insertPlayerAudits: %w", err)
+		}
+	}
+	return nil
+}
+
+func GetPlayerAudit(ctx context.Context, db *sql.DB, playerID int) ([]models.PlayerAudit, error) {
+	ctx, span := tracing.Start(ctx, "databases.GetPlayerAudit")
+	defer span.End()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT 
+		ID, PlayerID, Actor, Field, OldValue, NewValue, ChangedAt 
+		FROM PlayerAudit 
+		WHERE PlayerID = ? 
+		ORDER BY ID
+	`, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying database with GetPlayerAudit: %w", err)
+	}
+	defer rows.Close()
+
+	audits := []models.PlayerAudit{}
+	for rows.Next() {
+		var audit models.PlayerAudit
+		err := rows.Scan(
+			&audit.ID,
+			&audit.PlayerID,
+			&audit.Actor,
+			&audit.Field,
+			&audit.OldValue,
+			&audit.NewValue,
+			&audit.ChangedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row with GetPlayerAudit: %w", err)
+		}
+		audits = append(audits, audit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows with GetPlayerAudit: %w", err)
+	}
+
+	return audits, nil
+}
+
+// playerRankAudits returns the audit rows for the fields changed between two player ranks.
+func playerRankAudits(before, after models.PlayerRank, actor string) []models.PlayerAudit {
+	changedAt := auditTime()
+	audits := []models.PlayerAudit{}
+	if before.Name != after.Name {
+		audits = append(audits, models.PlayerAudit{
+			PlayerID:  after.ID,
+			Actor:     actor,
+			Field:     "name",
+			OldValue:  before.Name,
+			NewValue:  after.Name,
+			ChangedAt: changedAt,
+		})
+	}
+	if before.XP != after.XP {
+		audits = append(audits, models.PlayerAudit{
+			PlayerID:  after.ID,
+			Actor:     actor,
+			Field:     "xp",
+			OldValue:  strconv.Itoa(before.XP),
+			NewValue:  strconv.Itoa(after.XP),
+			ChangedAt: changedAt,
+		})
+	}
+	if before.LV != after.LV {
+		audits = append(audits, models.PlayerAudit{
+			PlayerID:  after.ID,
+			Actor:     actor,
+			Field:     "lv",
+			OldValue:  strconv.Itoa(before.LV),
+			NewValue:  strconv.Itoa(after.LV),
+			ChangedAt: changedAt,
+		})
+	}
+	profileFields := []struct {
+		field    string
+		old, new *string
+	}{
+		{"display_name", before.DisplayName, after.DisplayName},
+		{"avatar_url", before.AvatarURL, after.AvatarURL},
+		{"country", before.Country, after.Country},
+		{"locale", before.Locale, after.Locale},
+	}
+	for _, f := range profileFields {
+		if formatAuditString(f.old) != formatAuditString(f.new) {
+			audits = append(audits, models.PlayerAudit{
+				PlayerID:  after.ID,
+				Actor:     actor,
+				Field:     f.field,
+				OldValue:  formatAuditString(f.old),
+				NewValue:  formatAuditString(f.new),
+				ChangedAt: changedAt,
+			})
+		}
+	}
+	return audits
+}
+
+// auditTime is the timestamp stored for a change, truncated to the DATETIME precision.
+func auditTime() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+func formatAuditTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatAuditString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,220 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
+)
+
+// Register creates a player at the lowest level together with its credential
+// and returns the new player ID.
+func Register(ctx context.Context, db *sql.DB, username string, passwordHash string) (int, error) {
+	ctx, span := tracing.Start(ctx, "databases.Register")
+	defer span.End()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction with Register: %w", err)
+	}
+	defer tx.Rollback()
+
+	var levelID int
+	err = tx.QueryRowContext(ctx, `
+		SELECT 
+		ID
+		FROM Level 
+		ORDER BY LV
+		LIMIT 1
+	`).Scan(
+		&levelID,
+	)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("error registering without any level: %w", ErrLevelNotFound)
+	} else if err != nil {
+		return 0, fmt.Errorf("error querying database with Register: %w", err)
+	}
+
+	createdAt := auditTime()
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO Player (Name, LevelID, CreatedAt, UpdatedAt) 
+		VALUES (?, ?, ?, ?)
+	`, username, levelID, createdAt, createdAt)
+	if err != nil {
+		return 0, fmt.Errorf("error querying database with Register: %w", err)
+	}
+	id, _ := result.LastInsertId()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO PlayerCredential (PlayerID, Username, PasswordHash, CreatedAt) 
+		VALUES (?, ?, ?, ?)
+	`, id, username, passwordHash, createdAt)
+	if isDuplicateEntry(err) {
+		return 0, fmt.Errorf("error registering %q: %w", username, ErrDuplicateUsername)
+	} else if err != nil {
+		return 0, fmt.Errorf("error querying database with Register: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing transaction with Register: %w", err)
+	}
+	return int(id), nil
+}
+
+// GetCredential returns the credential of the username, deleted players cannot log in.
+func GetCredential(ctx context.Context, db *sql.DB, username string) (*models.Credential, error) {
+	ctx, span := tracing.Start(ctx, "databases.GetCredential")
+	defer span.End()
+
+	var credential models.Credential
+	err := db.QueryRowContext(ctx, `
+		SELECT 
+		C.PlayerID, C.Username, C.PasswordHash 
+		FROM PlayerCredential C 
+		INNER JOIN Player P ON C.PlayerID = P.ID 
+		WHERE C.Username = ? AND P.DeletedAt IS NULL
+	`, username).Scan(
+		&credential.PlayerID,
+		&credential.Username,
+		&credential.PasswordHash,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("error querying database with GetCredential: %w", err)
+	} else if err != nil {
+		return nil, fmt.Errorf("error scanning row with GetCredential: %w", err)
+	}
+	return &credential, nil
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertRefreshToken(ctx context.Context, db execer, token models.RefreshToken) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO RefreshToken (PlayerID, FamilyID, TokenHash, ExpiresAt, CreatedAt) 
+		VALUES (?, ?, ?, ?, ?)
+	`, token.PlayerID, token.FamilyID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error querying database with insertRefreshToken: %w", err)
+	}
+	return nil
+}
+
+func CreateRefreshToken(ctx context.Context, db *sql.DB, token models.RefreshToken) error {
+	ctx, span := tracing.Start(ctx, "databases.CreateRefreshToken")
+	defer span.End()
+
+	return insertRefreshToken(ctx, db, token)
+}
+
+// RotateRefreshToken revokes the refresh token with the hash and stores next
+// in its family for the same player, next.PlayerID and next.FamilyID are filled
+// from the revoked token. Presenting a token that was already rotated or revoked
+// revokes its whole family and returns ErrRefreshTokenReused.
+func RotateRefreshToken(ctx context.Context, db *sql.DB, tokenHash string, next *models.RefreshToken) error {
+	ctx, span := tracing.Start(ctx, "databases.RotateRefreshToken")
+	defer span.End()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction with RotateRefreshToken: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current models.RefreshToken
+	var deletedAt sql.NullTime
+	err = tx.QueryRowContext(ctx, `
+		SELECT 
+		T.ID, T.PlayerID, T.FamilyID, T.ExpiresAt, T.RevokedAt, P.DeletedAt 
+		FROM RefreshToken T 
+		INNER JOIN Player P ON T.PlayerID = P.ID 
+		WHERE T.TokenHash = ? 
+		FOR UPDATE
+	`, tokenHash).Scan(
+		&current.ID,
+		&current.PlayerID,
+		&current.FamilyID,
+		&current.ExpiresAt,
+		&current.RevokedAt,
+		&deletedAt,
+	)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("error rotating refresh token: %w", ErrInvalidRefreshToken)
+	} else if err != nil {
+		return fmt.Errorf("error scanning row with RotateRefreshToken: %w", err)
+	}
+
+	now := auditTime()
+	if current.RevokedAt != nil {
+		if err = revokeRefreshTokenFamily(ctx, tx, current.FamilyID, now); err != nil {
+			return err
+		}
+		if err = tx.Commit(); err != nil {
+			return fmt.Errorf("error committing transaction with RotateRefreshToken: %w", err)
+		}
+		return fmt.Errorf("error rotating refresh token of player %d: %w", current.PlayerID, ErrRefreshTokenReused)
+	}
+	if !current.ExpiresAt.After(now) || deletedAt.Valid {
+		return fmt.Errorf("error rotating refresh token: %w", ErrInvalidRefreshToken)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE RefreshToken 
+		SET RevokedAt = ? 
+		WHERE ID = ?
+	`, now, current.ID)
+	if err != nil {
+		return fmt.Errorf("error querying database with RotateRefreshToken: %w", err)
+	}
+
+	next.PlayerID = current.PlayerID
+	next.FamilyID = current.FamilyID
+	if err = insertRefreshToken(ctx, tx, *next); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction with RotateRefreshToken: %w", err)
+	}
+	return nil
+}
+
+// RevokeRefreshToken ends the session of a refresh token by revoking its whole family.
+func RevokeRefreshToken(ctx context.Context, db *sql.DB, tokenHash string) error {
+	ctx, span := tracing.Start(ctx, "databases.RevokeRefreshToken")
+	defer span.End()
+
+	var familyID string
+	err := db.QueryRowContext(ctx, `
+		SELECT 
+		FamilyID 
+		FROM RefreshToken 
+		WHERE TokenHash = ?
+	`, tokenHash).Scan(
+		&familyID,
+	)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("error revoking refresh token: %w", ErrInvalidRefreshToken)
+	} else if err != nil {
+		return fmt.Errorf("error scanning row with RevokeRefreshToken: %w", err)
+	}
+
+	return revokeRefreshTokenFamily(ctx, db, familyID, auditTime())
+}
+
+func revokeRefreshTokenFamily(ctx context.Context, db execer, familyID string, now time.Time) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE RefreshToken 
+		SET RevokedAt = ? 
+		WHERE FamilyID = ? AND RevokedAt IS NULL
+	`, now, familyID)
+	if err != nil {
+		return fmt.Errorf("error querying database with revokeRefreshTokenFamily: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
+)
+
+// validateImportRow returns why a row cannot be imported, or "" when it can.
+func validateImportRow(row models.PlayerImportRow, levelIDs map[int]int) string {
+	if row.Name == "" {
+		return "name is required"
+	}
+	if _, ok := levelIDs[row.LV]; !ok {
+		return fmt.Sprintf("level %d not found", row.LV)
+	}
+	return ""
+}
+
+// ImportPlayers creates one batch of players in a single transaction.
+// Invalid rows are reported as errors and rows whose name is already used
+// by an active player are skipped, so an import can safely be run again.
+// When the transaction fails every row of the batch is reported as an error.
+func ImportPlayers(ctx context.Context, db *sql.DB, rows []models.PlayerImportRow) ([]models.PlayerImportResult, error) {
+	ctx, span := tracing.Start(ctx, "databases.ImportPlayers")
+	defer span.End()
+
+	levels, err := GetLevelsData(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	levelIDs := make(map[int]int, len(levels))
+	for _, l := range levels {
+		levelIDs[l.LV] = l.ID
+	}
+
+	results := make([]models.PlayerImportResult, len(rows))
+	failBatch := func(err error) ([]models.PlayerImportResult, error) {
+		for i, row := range rows {
+			results[i] = models.PlayerImportResult{Line: row.Line, Status: models.ImportError, Reason: err.Error()}
+		}
+		return results, nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction with ImportPlayers: %w", err)
+	}
+	defer tx.Rollback()
+
+	createdAt := auditTime()
+	for i, row := range rows {
+		results[i] = models.PlayerImportResult{Line: row.Line}
+
+		if reason := validateImportRow(row, levelIDs); reason != "" {
+			results[i].Status = models.ImportError
+			results[i].Reason = reason
+			continue
+		}
+
+		var exists bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM Player WHERE Name = ? AND DeletedAt IS NULL
+			)
+		`, row.Name).Scan(
+			&exists,
+		)
+		if err != nil {
+			return failBatch(fmt.Errorf("error querying database with ImportPlayers: %w", err))
+		}
+		if exists {
+			results[i].Status = models.ImportSkipped
+			results[i].Reason = "player name already exists"
+			continue
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO Player (Name, LevelID, CreatedAt, UpdatedAt) 
+			VALUES (?, ?, ?, ?)
+		`, row.Name, levelIDs[row.LV], createdAt, createdAt)
+		if err != nil {
+			return failBatch(fmt.Errorf("error querying database with ImportPlayers: %w", err))
+		}
+		id, _ := result.LastInsertId()
+		results[i].Status = models.ImportCreated
+		results[i].ID = int(id)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return failBatch(fmt.Errorf("error committing transaction with ImportPlayers: %w", err))
+	}
+	return results, nil
+}
+
+// ExportPlayers calls fn for every active player ordered by ID while the rows
+// are read, so the whole result set is never held in memory.
+func ExportPlayers(ctx context.Context, db *sql.DB, fn func(models.PlayerRank) error) error {
+	ctx, span := tracing.Start(ctx, "databases.ExportPlayers")
+	defer span.End()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT 
+		P.ID as ID, 
+		P.Name as Name,
+		L.LV as LV,
+		P.XP as XP
+		FROM Player P
+		INNER JOIN 
+		Level L 
+		ON P.LevelID = L.ID
+		WHERE P.DeletedAt IS NULL
+		ORDER BY P.ID
+	`)
+	if err != nil {
+		return fmt.Errorf("error querying database with ExportPlayers: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var playerRank models.PlayerRank
+		err := rows.Scan(
+			&playerRank.ID,
+			&playerRank.Name,
+			&playerRank.LV,
+			&playerRank.XP,
+		)
+		if err != nil {
+			return fmt.Errorf("error scanning row with ExportPlayers: %w", err)
+		}
+		if err := fn(playerRank); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating over rows with ExportPlayers: %w", err)
+	}
+	return nil
+}
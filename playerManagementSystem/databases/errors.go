@@ -0,0 +1,41 @@
+package databases
+
+import (
+	"errors"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	// ErrDuplicateLV is returned when another level already uses the LV.
+	ErrDuplicateLV = errors.New("a level with this lv already exists")
+	// ErrLevelInUse is returned when deleting a level that players still reference.
+	ErrLevelInUse = errors.New("level is still referenced by players")
+	// ErrReassignLevelNotFound is returned when the level to move players to does not exist.
+	ErrReassignLevelNotFound = errors.New("level to reassign players to does not exist")
+	// ErrPlayerNotDeleted is returned when restoring a player that is not deleted.
+	ErrPlayerNotDeleted = errors.New("player is not deleted")
+	// ErrInvalidPatch is returned when a merge patch cannot be applied to a player.
+	ErrInvalidPatch = errors.New("invalid player patch")
+	// ErrVersionMismatch is returned when the player changed since the version given in If-Match.
+	ErrVersionMismatch = errors.New("player version does not match")
+	// ErrLevelNotFound is returned when no level has the requested LV.
+	ErrLevelNotFound = errors.New("level not found")
+	// ErrDuplicateUsername is returned when another account already uses the username.
+	ErrDuplicateUsername = errors.New("username is already taken")
+	// ErrInvalidRefreshToken is returned for an unknown, expired or revoked refresh token.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused is returned when a rotated refresh token is used again,
+	// the whole family of the token is revoked.
+	ErrRefreshTokenReused = errors.New("refresh token was already used or revoked")
+	// ErrAPIKeyInactive is returned when rotating an API key that is revoked or expired.
+	ErrAPIKeyInactive = errors.New("api key is revoked or expired")
+)
+
+// mysqlDuplicateEntry is the MySQL error number for a unique key violation.
+const mysqlDuplicateEntry = 1062
+
+func isDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry
+}
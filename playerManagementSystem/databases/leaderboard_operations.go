@@ -0,0 +1,121 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
+)
+
+// rankedPlayers is a derived table of the players not deleted with the dense
+// rank of their LV, computed once over all of them so the filters on it must
+// come outside of it.
+const rankedPlayers = `
+	(
+		SELECT 
+		P.ID as ID, 
+		P.Name as Name,
+		L.LV as LV,
+		P.XP as XP,
+		DENSE_RANK() OVER (ORDER BY L.LV DESC) as PlayerRank
+		FROM Player P
+		INNER JOIN 
+		Level L 
+		ON P.LevelID = L.ID
+		WHERE P.DeletedAt IS NULL
+	) R
+`
+
+func GetLeaderboard(ctx context.Context, db *sql.DB, leaderboardQuery models.LeaderboardQuery) (*models.Page[models.LeaderboardEntry], error) {
+	ctx, span := tracing.Start(ctx, "databases.GetLeaderboard")
+	defer span.End()
+
+	limit := pageLimit(leaderboardQuery.Limit)
+
+	query := `
+		SELECT 
+		R.ID, R.Name, R.LV, R.XP, R.PlayerRank 
+		FROM ` + rankedPlayers + `
+		WHERE 1 = 1
+	`
+	args := []interface{}{}
+
+	// Keyset pagination over (LV DESC, ID ASC)
+	if leaderboardQuery.AfterID != 0 {
+		var afterLV int
+		err := db.QueryRowContext(ctx, `
+			SELECT 
+			L.LV 
+			FROM Player P 
+			INNER JOIN Level L ON P.LevelID = L.ID 
+			WHERE P.ID = ? AND P.DeletedAt IS NULL
+		`, leaderboardQuery.AfterID).Scan(
+			&afterLV,
+		)
+		if err == sql.ErrNoRows {
+			return newPage([]models.LeaderboardEntry{}, limit, nil), nil
+		} else if err != nil {
+			return nil, fmt.Errorf("error querying database with GetLeaderboard: %w", err)
+		}
+		query += " AND (R.LV < ? OR (R.LV = ? AND R.ID > ?))"
+		args = append(args, afterLV, afterLV, leaderboardQuery.AfterID)
+	}
+
+	query += " ORDER BY R.LV DESC, R.ID ASC LIMIT ?"
+	args = append(args, limit+1)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying database with GetLeaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.LeaderboardEntry
+	for rows.Next() {
+		var entry models.LeaderboardEntry
+		err := rows.Scan(
+			&entry.ID,
+			&entry.Name,
+			&entry.LV,
+			&entry.XP,
+			&entry.Rank,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning row with GetLeaderboard: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows with GetLeaderboard: %w", err)
+	}
+
+	return newPage(entries, limit, func(e models.LeaderboardEntry) int { return e.ID }), nil
+}
+
+func GetPlayerRank(ctx context.Context, db *sql.DB, id int) (*models.LeaderboardEntry, error) {
+	ctx, span := tracing.Start(ctx, "databases.GetPlayerRank")
+	defer span.End()
+
+	var entry models.LeaderboardEntry
+	err := db.QueryRowContext(ctx, `
+		SELECT 
+		R.ID, R.Name, R.LV, R.XP, R.PlayerRank 
+		FROM `+rankedPlayers+`
+		WHERE R.ID = ?
+	`, id).Scan(
+		&entry.ID,
+		&entry.Name,
+		&entry.LV,
+		&entry.XP,
+		&entry.Rank,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("error querying database with GetPlayerRank: %w", err)
+	} else if err != nil {
+		return nil, fmt.Errorf("error scanning row with GetPlayerRank: %w", err)
+	}
+	return &entry, nil
+}
@@ -1,16 +1,22 @@
 package databases
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
 
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
 )
 
-func GetLevelsData(db *sql.DB) ([]models.Level, error) {
-	rows, err := db.Query(`
+func GetLevelsData(ctx context.Context, db *sql.DB) ([]models.Level, error) {
+	ctx, span := tracing.Start(ctx, "databases.GetLevelsData")
+	defer span.End()
+
+	rows, err := db.QueryContext(ctx, `
 		SELECT 
-		ID, Name, LV 
+		ID, Name, LV, XPThreshold 
 		FROM Level
 	`)
 	if err != nil {
@@ -24,6 +30,7 @@ func GetLevelsData(db *sql.DB) ([]models.Level, error) {
 			&l.ID,
 			&l.Name,
 			&l.LV,
+			&l.XPThreshold,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning row with GetLevelsData: %w", err)
@@ -33,14 +40,217 @@ func GetLevelsData(db *sql.DB) ([]models.Level, error) {
 	return levels, nil
 }
 
-func AddLevel(db *sql.DB, name string, lv int) (int, error) {
-	result, err := db.Exec(`
-		INSERT INTO Level (Name, LV) 
-		VALUES (?, ?)
-	`, name, lv)
-	if err != nil {
+func AddLevel(ctx context.Context, db *sql.DB, level models.Level) (int, error) {
+	ctx, span := tracing.Start(ctx, "databases.AddLevel")
+	defer span.End()
+
+	result, err := db.ExecContext(ctx, `
+		INSERT INTO Level (Name, LV, XPThreshold) 
+		SELECT 
+		?, ?, ? 
+		FROM DUAL 
+		WHERE NOT EXISTS (
+			SELECT 1 FROM Level WHERE LV = ?
+		)
+	`, level.Name, level.LV, level.XPThreshold, level.LV)
+	if isDuplicateEntry(err) {
+		return 0, fmt.Errorf("error querying database with AddLevel: %w", ErrDuplicateLV)
+	} else if err != nil {
 		return 0, fmt.Errorf("error querying database with AddLevel: %w", err)
 	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("error querying database with AddLevel: %w", ErrDuplicateLV)
+	}
+
 	id, _ := result.LastInsertId()
 	return int(id), nil
 }
+
+func GetLevel(ctx context.Context, db *sql.DB, id int) (*models.Level, error) {
+	ctx, span := tracing.Start(ctx, "databases.GetLevel")
+	defer span.End()
+
+	var level models.Level
+	err := db.QueryRowContext(ctx, `
+		SELECT 
+		ID, Name, LV, XPThreshold 
+		FROM Level 
+		WHERE ID = ?
+	`, id).Scan(
+		&level.ID,
+		&level.Name,
+		&level.LV,
+		&level.XPThreshold,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("error querying database with GetLevel: %w", err)
+	} else if err != nil {
+		return nil, fmt.Errorf("error scanning row with GetLevel: %w", err)
+	}
+	return &level, nil
+}
+
+func UpdateLevel(ctx context.Context, db *sql.DB, level models.Level) error {
+	ctx, span := tracing.Start(ctx, "databases.UpdateLevel")
+	defer span.End()
+
+	var exists bool
+	err := db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM Level WHERE LV = ? AND ID <> ?
+		)
+	`, level.LV, level.ID).Scan(
+		&exists,
+	)
+	if err != nil {
+		return fmt.Errorf("error querying database with UpdateLevel: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("error updating level with id %d: %w", level.ID, ErrDuplicateLV)
+	}
+
+	result, err := db.ExecContext(ctx, `
+		UPDATE Level 
+		SET Name = ?, LV = ?, XPThreshold = ? 
+		WHERE ID = ?
+	`, level.Name, level.LV, level.XPThreshold, level.ID)
+	if isDuplicateEntry(err) {
+		return fmt.Errorf("error updating level with id %d: %w", level.ID, ErrDuplicateLV)
+	} else if err != nil {
+		return fmt.Errorf("error querying database with UpdateLevel: %w", err)
+	}
+
+	// RowsAffected is 0 when nothing changed, so confirm the level exists
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		if _, err := GetLevel(ctx, db, level.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteLevel removes a level. When players still reference it, the delete is
+// refused with ErrLevelInUse unless reassignLV names the level to move them to,
+// the moved players get a new version and an audit row of the actor.
+func DeleteLevel(ctx context.Context, db *sql.DB, id int, reassignLV *int, actor string) error {
+	ctx, span := tracing.Start(ctx, "databases.DeleteLevel")
+	defer span.End()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction with DeleteLevel: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the level so no player can be moved onto it meanwhile
+	var lv int
+	err = tx.QueryRowContext(ctx, `
+		SELECT 
+		LV 
+		FROM Level 
+		WHERE ID = ? 
+		FOR UPDATE
+	`, id).Scan(
+		&lv,
+	)
+	if err != nil {
+		return fmt.Errorf("error querying database with DeleteLevel: %w", err)
+	}
+
+	playerIDs, err := levelPlayerIDs(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if len(playerIDs) > 0 {
+		if reassignLV == nil {
+			return fmt.Errorf("error deleting level with id %d: %w", id, ErrLevelInUse)
+		}
+
+		var targetID int
+		err = tx.QueryRowContext(ctx, `
+			SELECT 
+			ID 
+			FROM Level 
+			WHERE LV = ? AND ID <> ? 
+			FOR UPDATE
+		`, *reassignLV, id).Scan(
+			&targetID,
+		)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("error deleting level with id %d: %w", id, ErrReassignLevelNotFound)
+		} else if err != nil {
+			return fmt.Errorf("error querying database with DeleteLevel: %w", err)
+		}
+
+		changedAt := auditTime()
+		_, err = tx.ExecContext(ctx, `
+			UPDATE Player 
+			SET LevelID = ?, UpdatedAt = ?, Version = Version + 1 
+			WHERE LevelID = ?
+		`, targetID, changedAt, id)
+		if err != nil {
+			return fmt.Errorf("error reassigning players with DeleteLevel: %w", err)
+		}
+
+		audits := make([]models.PlayerAudit, 0, len(playerIDs))
+		for _, playerID := range playerIDs {
+			audits = append(audits, models.PlayerAudit{
+				PlayerID:  playerID,
+				Actor:     actor,
+				Field:     "lv",
+				OldValue:  strconv.Itoa(lv),
+				NewValue:  strconv.Itoa(*reassignLV),
+				ChangedAt: changedAt,
+			})
+		}
+		if err := insertPlayerAudits(ctx, tx, audits); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM Level 
+		WHERE ID = ?
+	`, id)
+	if err != nil {
+		return fmt.Errorf("error querying database with DeleteLevel: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction with DeleteLevel: %w", err)
+	}
+	return nil
+}
+
+// levelPlayerIDs locks the players of a level, deleted ones included, and returns their IDs.
+func levelPlayerIDs(ctx context.Context, tx *sql.Tx, levelID int) ([]int, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT 
+		ID 
+		FROM Player 
+		WHERE LevelID = ? 
+		ORDER BY ID 
+		FOR UPDATE
+	`, levelID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying database with levelPlayerIDs: %w", err)
+	}
+	defer rows.Close()
+
+	var playerIDs []int
+	for rows.Next() {
+		var playerID int
+		if err := rows.Scan(&playerID); err != nil {
+			return nil, fmt.Errorf("error scanning row with levelPlayerIDs: %w", err)
+		}
+		playerIDs = append(playerIDs, playerID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows with levelPlayerIDs: %w", err)
+	}
+	return playerIDs, nil
+}
@@ -0,0 +1,853 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+)
+
+// MemoryStore implements Store in process memory.
+// It is safe for concurrent use and is meant for unit tests and local demos.
+type MemoryStore struct {
+	mu               sync.RWMutex
+	players          map[int]models.Player
+	levels           map[int]models.Level
+	audits           []models.PlayerAudit
+	credentials      map[string]models.Credential
+	roles            map[int][]string
+	apiKeys          map[int64]models.APIKey
+	refreshTokens    map[string]models.RefreshToken
+	lastPlayer       int
+	lastLevel        int
+	lastRefreshToken int64
+	lastAPIKey       int64
+}
+
+// NewMemoryStore returns an empty store with the starting level inserted by level.sql.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		players:       make(map[int]models.Player),
+		levels:        map[int]models.Level{1: {ID: 1, Name: "Beginner", LV: 1}},
+		credentials:   make(map[string]models.Credential),
+		roles:         make(map[int][]string),
+		apiKeys:       make(map[int64]models.APIKey),
+		refreshTokens: make(map[string]models.RefreshToken),
+		lastLevel:     1,
+	}
+}
+
+// levelIDByLV returns the ID of the level with the given LV, the caller must hold the lock.
+func (s *MemoryStore) levelIDByLV(lv int) (int, bool) {
+	for _, l := range s.levels {
+		if l.LV == lv {
+			return l.ID, true
+		}
+	}
+	return 0, false
+}
+
+// lowestLevelID returns the ID of the level with the lowest LV, the caller must hold the lock.
+func (s *MemoryStore) lowestLevelID() (int, bool) {
+	var lowest *models.Level
+	for _, l := range s.levels {
+		if lowest == nil || l.LV < lowest.LV {
+			l := l
+			lowest = &l
+		}
+	}
+	if lowest == nil {
+		return 0, false
+	}
+	return lowest.ID, true
+}
+
+// activePlayer returns the player with the given ID unless it is soft deleted,
+// the caller must hold the lock.
+func (s *MemoryStore) activePlayer(id int) (models.Player, bool) {
+	p, ok := s.players[id]
+	if !ok || p.DeletedAt != nil {
+		return models.Player{}, false
+	}
+	return p, true
+}
+
+// audit records the audit rows with their IDs, the caller must hold the write lock.
+func (s *MemoryStore) audit(audits []models.PlayerAudit) {
+	for _, a := range audits {
+		a.ID = int64(len(s.audits) + 1)
+		s.audits = append(s.audits, a)
+	}
+}
+
+// playerRank joins a player to its level, the caller must hold the lock.
+func (s *MemoryStore) playerRank(p models.Player) models.PlayerRank {
+	return models.PlayerRank{
+		ID:        p.ID,
+		Name:      p.Name,
+		LV:        s.levels[p.LevelID].LV,
+		XP:        p.XP,
+		DeletedAt: p.DeletedAt,
+	}
+}
+
+// playerProfile joins a player to its level with the profile fields, the caller must hold the lock.
+func (s *MemoryStore) playerProfile(p models.Player) models.PlayerRank {
+	playerRank := s.playerRank(p)
+	playerRank.DisplayName = p.DisplayName
+	playerRank.AvatarURL = p.AvatarURL
+	playerRank.Country = p.Country
+	playerRank.Locale = p.Locale
+	playerRank.CreatedAt = &p.CreatedAt
+	playerRank.UpdatedAt = &p.UpdatedAt
+	playerRank.Version = p.Version
+	return playerRank
+}
+
+// touch marks a player as changed, the caller must hold the write lock.
+func (s *MemoryStore) touch(p *models.Player, at time.Time) {
+	p.UpdatedAt = at
+	p.Version++
+}
+
+// playerRankLess orders player ranks by a sort key of PlayerQuery, then by ID.
+func playerRankLess(sort string) func(a, b models.PlayerRank) bool {
+	return func(a, b models.PlayerRank) bool {
+		switch sort {
+		case "name":
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case "lv":
+			if a.LV != b.LV {
+				return a.LV < b.LV
+			}
+		}
+		return a.ID < b.ID
+	}
+}
+
+func (s *MemoryStore) GetPlayersData(ctx context.Context, playerQuery models.PlayerQuery) (*models.Page[models.PlayerRank], error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	limit := pageLimit(playerQuery.Limit)
+	less := playerRankLess(playerQuery.Sort)
+	if playerQuery.Order == "desc" {
+		asc := less
+		less = func(a, b models.PlayerRank) bool { return asc(b, a) }
+	}
+
+	var anchor *models.PlayerRank
+	if playerQuery.AfterID != 0 {
+		p, ok := s.players[playerQuery.AfterID]
+		if !ok || (p.DeletedAt != nil && !playerQuery.IncludeDeleted) {
+			return newPage([]models.PlayerRank{}, limit, nil), nil
+		}
+		playerRank := s.playerRank(p)
+		anchor = &playerRank
+	}
+
+	namePrefix := strings.ToLower(playerQuery.NamePrefix)
+
+	var playerRanks []models.PlayerRank
+	for _, p := range s.players {
+		if _, ok := s.levels[p.LevelID]; !ok {
+			continue
+		}
+		if p.DeletedAt != nil && !playerQuery.IncludeDeleted {
+			continue
+		}
+		playerRank := s.playerProfile(p)
+		if playerQuery.MinLV != nil && playerRank.LV < *playerQuery.MinLV {
+			continue
+		}
+		if playerQuery.MaxLV != nil && playerRank.LV > *playerQuery.MaxLV {
+			continue
+		}
+		if !strings.HasPrefix(strings.ToLower(playerRank.Name), namePrefix) {
+			continue
+		}
+		if anchor != nil && !less(*anchor, playerRank) {
+			continue
+		}
+		playerRanks = append(playerRanks, playerRank)
+	}
+	sort.Slice(playerRanks, func(i, j int) bool {
+		return less(playerRanks[i], playerRanks[j])
+	})
+	if len(playerRanks) > limit+1 {
+		playerRanks = playerRanks[:limit+1]
+	}
+	return newPage(playerRanks, limit, func(p models.PlayerRank) int { return p.ID }), nil
+}
+
+func (s *MemoryStore) AddPlayer(ctx context.Context, name string, lv int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	levelID, ok := s.levelIDByLV(lv)
+	if !ok {
+		return 0, fmt.Errorf("error querying database with AddPlayer: level %d not found", lv)
+	}
+	createdAt := auditTime()
+	s.lastPlayer++
+	s.players[s.lastPlayer] = models.Player{
+		ID:        s.lastPlayer,
+		Name:      name,
+		LevelID:   levelID,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		Version:   1,
+	}
+	return s.lastPlayer, nil
+}
+
+func (s *MemoryStore) GetPlayer(ctx context.Context, id int) (*models.PlayerRank, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.activePlayer(id)
+	if !ok {
+		return nil, fmt.Errorf("error querying database with GetPlayer: %w", sql.ErrNoRows)
+	}
+	playerRank := s.playerProfile(p)
+	return &playerRank, nil
+}
+
+func (s *MemoryStore) SearchPlayers(ctx context.Context, searchQuery models.PlayerSearchQuery) ([]models.PlayerSearchResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var candidates []models.PlayerRank
+	for _, p := range s.players {
+		if _, ok := s.levels[p.LevelID]; !ok || p.DeletedAt != nil {
+			continue
+		}
+		candidates = append(candidates, s.playerProfile(p))
+	}
+	return rankSearchResults(candidates, searchQuery.Q, searchLimit(searchQuery.Limit)), nil
+}
+
+func (s *MemoryStore) UpdatePlayer(ctx context.Context, playerRank models.PlayerRank, actor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if playerRank.LV == 0 && playerRank.Name == "" {
+		return fmt.Errorf("no fields update for player with id: %d", playerRank.ID)
+	}
+
+	p, ok := s.activePlayer(playerRank.ID)
+	if !ok {
+		return fmt.Errorf("no rows were updated, player with id %d may not exist: %w", playerRank.ID, sql.ErrNoRows)
+	}
+	before := s.playerRank(p)
+
+	if playerRank.LV != 0 {
+		levelID, ok := s.levelIDByLV(playerRank.LV)
+		if !ok {
+			return fmt.Errorf("error updating player with id %d to lv %d: %w", playerRank.ID, playerRank.LV, ErrLevelNotFound)
+		}
+		p.LevelID = levelID
+	}
+
+	if playerRank.Name != "" {
+		p.Name = playerRank.Name
+	}
+
+	audits := playerRankAudits(before, s.playerRank(p), actor)
+	if len(audits) == 0 {
+		return nil
+	}
+	s.touch(&p, auditTime())
+	s.players[p.ID] = p
+	s.audit(audits)
+	return nil
+}
+
+func (s *MemoryStore) PatchPlayer(ctx context.Context, id int, patch []byte, version int, actor string) (*models.PlayerRank, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.activePlayer(id)
+	if !ok {
+		return nil, fmt.Errorf("error querying database with PatchPlayer: %w", sql.ErrNoRows)
+	}
+	if version != 0 && version != p.Version {
+		return nil, fmt.Errorf("error patching player with id %d at version %d: %w", id, p.Version, ErrVersionMismatch)
+	}
+
+	before := s.playerProfile(p)
+	after, err := applyPlayerPatch(before, patch)
+	if err != nil {
+		return nil, err
+	}
+	audits := playerRankAudits(before, after, actor)
+	if len(audits) == 0 {
+		return &before, nil
+	}
+
+	levelID, ok := s.levelIDByLV(after.LV)
+	if !ok {
+		return nil, fmt.Errorf("%w: level %d not found", ErrInvalidPatch, after.LV)
+	}
+	p.Name = after.Name
+	p.LevelID = levelID
+	p.DisplayName = after.DisplayName
+	p.AvatarURL = after.AvatarURL
+	p.Country = after.Country
+	p.Locale = after.Locale
+	s.touch(&p, auditTime())
+
+	s.players[id] = p
+	s.audit(audits)
+	patched := s.playerProfile(p)
+	return &patched, nil
+}
+
+func (s *MemoryStore) DeletePlayer(ctx context.Context, id int, actor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.activePlayer(id)
+	if !ok {
+		return fmt.Errorf("no rows were deleted, player with id %d may not exist: %w", id, sql.ErrNoRows)
+	}
+	deletedAt := auditTime()
+	p.DeletedAt = &deletedAt
+	s.touch(&p, deletedAt)
+	s.players[id] = p
+	s.audit([]models.PlayerAudit{{
+		PlayerID:  id,
+		Actor:     actor,
+		Field:     "deleted_at",
+		NewValue:  formatAuditTime(&deletedAt),
+		ChangedAt: deletedAt,
+	}})
+	return nil
+}
+
+func (s *MemoryStore) RestorePlayer(ctx context.Context, id int, actor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.players[id]
+	if !ok {
+		return fmt.Errorf("error querying database with RestorePlayer: %w", sql.ErrNoRows)
+	}
+	if p.DeletedAt == nil {
+		return fmt.Errorf("error restoring player with id %d: %w", id, ErrPlayerNotDeleted)
+	}
+	deletedAt := p.DeletedAt
+	restoredAt := auditTime()
+	p.DeletedAt = nil
+	s.touch(&p, restoredAt)
+	s.players[id] = p
+	s.audit([]models.PlayerAudit{{
+		PlayerID:  id,
+		Actor:     actor,
+		Field:     "deleted_at",
+		OldValue:  formatAuditTime(deletedAt),
+		ChangedAt: restoredAt,
+	}})
+	return nil
+}
+
+func (s *MemoryStore) GetPlayerAudit(ctx context.Context, id int) ([]models.PlayerAudit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	audits := []models.PlayerAudit{}
+	for _, a := range s.audits {
+		if a.PlayerID == id {
+			audits = append(audits, a)
+		}
+	}
+	return audits, nil
+}
+
+func (s *MemoryStore) ImportPlayers(ctx context.Context, rows []models.PlayerImportRow) ([]models.PlayerImportResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	levelIDs := make(map[int]int, len(s.levels))
+	for _, l := range s.levels {
+		levelIDs[l.LV] = l.ID
+	}
+
+	createdAt := auditTime()
+	results := make([]models.PlayerImportResult, len(rows))
+	for i, row := range rows {
+		results[i] = models.PlayerImportResult{Line: row.Line}
+
+		if reason := validateImportRow(row, levelIDs); reason != "" {
+			results[i].Status = models.ImportError
+			results[i].Reason = reason
+			continue
+		}
+
+		exists := false
+		for _, p := range s.players {
+			if p.DeletedAt == nil && strings.EqualFold(p.Name, row.Name) {
+				exists = true
+				break
+			}
+		}
+		if exists {
+			results[i].Status = models.ImportSkipped
+			results[i].Reason = "player name already exists"
+			continue
+		}
+
+		s.lastPlayer++
+		s.players[s.lastPlayer] = models.Player{
+			ID:        s.lastPlayer,
+			Name:      row.Name,
+			LevelID:   levelIDs[row.LV],
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+			Version:   1,
+		}
+		results[i].Status = models.ImportCreated
+		results[i].ID = s.lastPlayer
+	}
+	return results, nil
+}
+
+func (s *MemoryStore) ExportPlayers(ctx context.Context, fn func(models.PlayerRank) error) error {
+	// Take a snapshot so fn runs without holding the lock
+	s.mu.RLock()
+	var playerRanks []models.PlayerRank
+	for _, p := range s.players {
+		if _, ok := s.levels[p.LevelID]; !ok || p.DeletedAt != nil {
+			continue
+		}
+		playerRanks = append(playerRanks, s.playerRank(p))
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(playerRanks, func(i, j int) bool {
+		return playerRanks[i].ID < playerRanks[j].ID
+	})
+	for _, playerRank := range playerRanks {
+		if err := fn(playerRank); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// leaderboard returns every player with its dense rank ordered by LV DESC, ID ASC,
+// the caller must hold the lock.
+func (s *MemoryStore) leaderboard() []models.LeaderboardEntry {
+	var entries []models.LeaderboardEntry
+	for _, p := range s.players {
+		if _, ok := s.levels[p.LevelID]; !ok || p.DeletedAt != nil {
+			continue
+		}
+		entries = append(entries, models.LeaderboardEntry{PlayerRank: s.playerRank(p)})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].LV != entries[j].LV {
+			return entries[i].LV > entries[j].LV
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	for i := range entries {
+		switch {
+		case i == 0:
+			entries[i].Rank = 1
+		case entries[i].LV == entries[i-1].LV:
+			entries[i].Rank = entries[i-1].Rank
+		default:
+			entries[i].Rank = entries[i-1].Rank + 1
+		}
+	}
+	return entries
+}
+
+func (s *MemoryStore) GetLeaderboard(ctx context.Context, leaderboardQuery models.LeaderboardQuery) (*models.Page[models.LeaderboardEntry], error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	limit := pageLimit(leaderboardQuery.Limit)
+	entries := s.leaderboard()
+
+	if leaderboardQuery.AfterID != 0 {
+		start := len(entries)
+		for i, e := range entries {
+			if e.ID == leaderboardQuery.AfterID {
+				start = i + 1
+				break
+			}
+		}
+		entries = entries[start:]
+	}
+
+	if len(entries) > limit+1 {
+		entries = entries[:limit+1]
+	}
+	return newPage(entries, limit, func(e models.LeaderboardEntry) int { return e.ID }), nil
+}
+
+func (s *MemoryStore) GetPlayerRank(ctx context.Context, id int) (*models.LeaderboardEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, e := range s.leaderboard() {
+		if e.ID == id {
+			return &e, nil
+		}
+	}
+	return nil, fmt.Errorf("error querying database with GetPlayerRank: %w", sql.ErrNoRows)
+}
+
+func (s *MemoryStore) AwardXP(ctx context.Context, id int, amount int, actor string) (*models.XPAward, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.activePlayer(id)
+	if !ok {
+		return nil, fmt.Errorf("error querying database with AwardXP: %w", sql.ErrNoRows)
+	}
+
+	award := models.XPAward{Before: s.playerRank(p)}
+	p.XP += amount
+
+	// Promote to the highest level whose threshold is reached, never demote
+	current := s.levels[p.LevelID]
+	for _, l := range s.levels {
+		if l.XPThreshold <= p.XP && l.LV > current.LV {
+			current = l
+		}
+	}
+	p.LevelID = current.ID
+	s.touch(&p, auditTime())
+
+	s.players[id] = p
+	award.After = s.playerRank(p)
+	s.audit(playerRankAudits(award.Before, award.After, actor))
+	return &award, nil
+}
+
+func (s *MemoryStore) Register(ctx context.Context, username string, passwordHash string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	levelID, ok := s.lowestLevelID()
+	if !ok {
+		return 0, fmt.Errorf("error registering without any level: %w", ErrLevelNotFound)
+	}
+	// Usernames are unique regardless of case, like the MySQL collation
+	key := strings.ToLower(username)
+	if _, ok := s.credentials[key]; ok {
+		return 0, fmt.Errorf("error registering %q: %w", username, ErrDuplicateUsername)
+	}
+
+	createdAt := auditTime()
+	s.lastPlayer++
+	s.players[s.lastPlayer] = models.Player{
+		ID:        s.lastPlayer,
+		Name:      username,
+		LevelID:   levelID,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		Version:   1,
+	}
+	s.credentials[key] = models.Credential{
+		PlayerID:     s.lastPlayer,
+		Username:     username,
+		PasswordHash: passwordHash,
+	}
+	return s.lastPlayer, nil
+}
+
+func (s *MemoryStore) GetCredential(ctx context.Context, username string) (*models.Credential, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	credential, ok := s.credentials[strings.ToLower(username)]
+	if !ok {
+		return nil, fmt.Errorf("error querying database with GetCredential: %w", sql.ErrNoRows)
+	}
+	if _, ok := s.activePlayer(credential.PlayerID); !ok {
+		return nil, fmt.Errorf("error querying database with GetCredential: %w", sql.ErrNoRows)
+	}
+	return &credential, nil
+}
+
+// addRefreshToken stores a refresh token with its ID, the caller must hold the write lock.
+func (s *MemoryStore) addRefreshToken(token models.RefreshToken) {
+	s.lastRefreshToken++
+	token.ID = s.lastRefreshToken
+	s.refreshTokens[token.TokenHash] = token
+}
+
+// revokeRefreshTokenFamily revokes every token of a family, the caller must hold the write lock.
+func (s *MemoryStore) revokeRefreshTokenFamily(familyID string, now time.Time) {
+	for hash, token := range s.refreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+			s.refreshTokens[hash] = token
+		}
+	}
+}
+
+func (s *MemoryStore) CreateRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addRefreshToken(token)
+	return nil
+}
+
+func (s *MemoryStore) RotateRefreshToken(ctx context.Context, tokenHash string, next *models.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.refreshTokens[tokenHash]
+	if !ok {
+		return fmt.Errorf("error rotating refresh token: %w", ErrInvalidRefreshToken)
+	}
+
+	now := auditTime()
+	if current.RevokedAt != nil {
+		s.revokeRefreshTokenFamily(current.FamilyID, now)
+		return fmt.Errorf("error rotating refresh token of player %d: %w", current.PlayerID, ErrRefreshTokenReused)
+	}
+	if _, ok := s.activePlayer(current.PlayerID); !ok || !current.ExpiresAt.After(now) {
+		return fmt.Errorf("error rotating refresh token: %w", ErrInvalidRefreshToken)
+	}
+
+	current.RevokedAt = &now
+	s.refreshTokens[tokenHash] = current
+	next.PlayerID = current.PlayerID
+	next.FamilyID = current.FamilyID
+	s.addRefreshToken(*next)
+	return nil
+}
+
+func (s *MemoryStore) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.refreshTokens[tokenHash]
+	if !ok {
+		return fmt.Errorf("error revoking refresh token: %w", ErrInvalidRefreshToken)
+	}
+	s.revokeRefreshTokenFamily(token.FamilyID, auditTime())
+	return nil
+}
+
+func (s *MemoryStore) GetRoles(ctx context.Context, playerID int) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.activePlayer(playerID); !ok {
+		return nil, fmt.Errorf("error querying database with GetRoles: %w", sql.ErrNoRows)
+	}
+	return append([]string{}, s.roles[playerID]...), nil
+}
+
+func (s *MemoryStore) SetRoles(ctx context.Context, playerID int, roles []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.activePlayer(playerID); !ok {
+		return fmt.Errorf("error querying database with SetRoles: %w", sql.ErrNoRows)
+	}
+	s.roles[playerID] = uniqueRoles(roles)
+	return nil
+}
+
+// addAPIKey stores an API key with its ID, the caller must hold the write lock.
+func (s *MemoryStore) addAPIKey(key *models.APIKey) {
+	s.lastAPIKey++
+	key.ID = s.lastAPIKey
+	s.apiKeys[key.ID] = *key
+}
+
+func (s *MemoryStore) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addAPIKey(key)
+	return nil
+}
+
+func (s *MemoryStore) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := []models.APIKey{}
+	for _, key := range s.apiKeys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ID > keys[j].ID
+	})
+	return keys, nil
+}
+
+func (s *MemoryStore) RevokeAPIKey(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, ok := s.apiKeys[id]
+	if !ok {
+		return fmt.Errorf("error querying database with RevokeAPIKey: %w", sql.ErrNoRows)
+	}
+	if key.RevokedAt == nil {
+		now := auditTime()
+		key.RevokedAt = &now
+		s.apiKeys[id] = key
+	}
+	return nil
+}
+
+func (s *MemoryStore) RotateAPIKey(ctx context.Context, id int64, next *models.APIKey, overlapUntil time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.apiKeys[id]
+	if !ok {
+		return fmt.Errorf("error querying database with RotateAPIKey: %w", sql.ErrNoRows)
+	}
+	if current.RevokedAt != nil || (current.ExpiresAt != nil && !current.ExpiresAt.After(next.CreatedAt)) {
+		return fmt.Errorf("error rotating api key %d: %w", id, ErrAPIKeyInactive)
+	}
+	if current.ExpiresAt == nil || overlapUntil.Before(*current.ExpiresAt) {
+		current.ExpiresAt = &overlapUntil
+		s.apiKeys[id] = current
+	}
+
+	next.Name = current.Name
+	next.Scopes = current.Scopes
+	next.RotatedFromID = &current.ID
+	s.addAPIKey(next)
+	return nil
+}
+
+func (s *MemoryStore) VerifyAPIKey(ctx context.Context, keyHash string) (int64, []string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := auditTime()
+	for id, key := range s.apiKeys {
+		if key.KeyHash != keyHash {
+			continue
+		}
+		if key.RevokedAt != nil || (key.ExpiresAt != nil && !key.ExpiresAt.After(now)) {
+			break
+		}
+		key.LastUsedAt = &now
+		s.apiKeys[id] = key
+		return id, key.Scopes, nil
+	}
+	return 0, nil, fmt.Errorf("error querying database with VerifyAPIKey: %w", sql.ErrNoRows)
+}
+
+func (s *MemoryStore) GetLevelsData(ctx context.Context) ([]models.Level, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var levels []models.Level
+	for _, l := range s.levels {
+		levels = append(levels, l)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].ID < levels[j].ID
+	})
+	return levels, nil
+}
+
+func (s *MemoryStore) AddLevel(ctx context.Context, level models.Level) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.levelIDByLV(level.LV); ok {
+		return 0, fmt.Errorf("error querying database with AddLevel: %w", ErrDuplicateLV)
+	}
+
+	s.lastLevel++
+	level.ID = s.lastLevel
+	s.levels[level.ID] = level
+	return level.ID, nil
+}
+
+func (s *MemoryStore) GetLevel(ctx context.Context, id int) (*models.Level, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	level, ok := s.levels[id]
+	if !ok {
+		return nil, fmt.Errorf("error querying database with GetLevel: %w", sql.ErrNoRows)
+	}
+	return &level, nil
+}
+
+func (s *MemoryStore) UpdateLevel(ctx context.Context, level models.Level) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.levels[level.ID]; !ok {
+		return fmt.Errorf("error querying database with UpdateLevel: %w", sql.ErrNoRows)
+	}
+	if levelID, ok := s.levelIDByLV(level.LV); ok && levelID != level.ID {
+		return fmt.Errorf("error updating level with id %d: %w", level.ID, ErrDuplicateLV)
+	}
+	s.levels[level.ID] = level
+	return nil
+}
+
+func (s *MemoryStore) DeleteLevel(ctx context.Context, id int, reassignLV *int, actor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	level, ok := s.levels[id]
+	if !ok {
+		return fmt.Errorf("error querying database with DeleteLevel: %w", sql.ErrNoRows)
+	}
+
+	var referencing []int
+	for _, p := range s.players {
+		if p.LevelID == id {
+			referencing = append(referencing, p.ID)
+		}
+	}
+
+	if len(referencing) > 0 {
+		if reassignLV == nil {
+			return fmt.Errorf("error deleting level with id %d: %w", id, ErrLevelInUse)
+		}
+		targetID, ok := s.levelIDByLV(*reassignLV)
+		if !ok || targetID == id {
+			return fmt.Errorf("error deleting level with id %d: %w", id, ErrReassignLevelNotFound)
+		}
+		sort.Ints(referencing)
+		changedAt := auditTime()
+		for _, playerID := range referencing {
+			p := s.players[playerID]
+			p.LevelID = targetID
+			s.touch(&p, changedAt)
+			s.players[playerID] = p
+			s.audit([]models.PlayerAudit{{
+				PlayerID:  playerID,
+				Actor:     actor,
+				Field:     "lv",
+				OldValue:  strconv.Itoa(level.LV),
+				NewValue:  strconv.Itoa(*reassignLV),
+				ChangedAt: changedAt,
+			}})
+		}
+	}
+
+	delete(s.levels, id)
+	return nil
+}
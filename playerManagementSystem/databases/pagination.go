@@ -0,0 +1,45 @@
+package databases
+
+import (
+	"strings"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+// pageLimit returns the limit to use for a list query.
+func pageLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultPageLimit
+	}
+	if limit > MaxPageLimit {
+		return MaxPageLimit
+	}
+	return limit
+}
+
+// newPage builds a page from up to limit+1 fetched items,
+// the extra item only tells there is a next page.
+func newPage[T any](items []T, limit int, cursor func(T) int) *models.Page[T] {
+	page := &models.Page[T]{Data: items}
+	if len(items) > limit {
+		page.Data = items[:limit]
+		next := cursor(page.Data[limit-1])
+		page.NextCursor = &next
+	}
+	if page.Data == nil {
+		page.Data = []T{}
+	}
+	return page
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
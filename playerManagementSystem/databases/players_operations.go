@@ -1,24 +1,93 @@
 package databases
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
 )
 
-func GetPlayersData(db *sql.DB) ([]models.PlayerRank, error) {
-	rows, err := db.Query(`
+// playerSortColumns maps a sort key of PlayerQuery to its column in the
+// listed rows (P, L) and in the anchor row of the cursor (AP, AL).
+var playerSortColumns = map[string][2]string{
+	"id":   {"P.ID", "AP.ID"},
+	"name": {"P.Name", "AP.Name"},
+	"lv":   {"L.LV", "AL.LV"},
+}
+
+func GetPlayersData(ctx context.Context, db *sql.DB, playerQuery models.PlayerQuery) (*models.Page[models.PlayerRank], error) {
+	ctx, span := tracing.Start(ctx, "databases.GetPlayersData")
+	defer span.End()
+
+	limit := pageLimit(playerQuery.Limit)
+	columns, ok := playerSortColumns[playerQuery.Sort]
+	if !ok {
+		columns = playerSortColumns["id"]
+	}
+	direction, comparison := "ASC", ">"
+	if playerQuery.Order == "desc" {
+		direction, comparison = "DESC", "<"
+	}
+
+	query := `
 		SELECT 
 		P.ID as ID, 
 		P.Name as Name,
-		L.LV as LV
+		L.LV as LV,
+		P.XP as XP,
+	` + playerProfileColumns + `
 		FROM Player P
 		INNER JOIN 
 		Level L 
 		ON P.LevelID = L.ID
-		ORDER BY ID
-	`)
+		WHERE 1 = 1
+	`
+	args := []interface{}{}
+
+	if !playerQuery.IncludeDeleted {
+		query += " AND P.DeletedAt IS NULL"
+	}
+
+	if playerQuery.MinLV != nil {
+		query += " AND L.LV >= ?"
+		args = append(args, *playerQuery.MinLV)
+	}
+
+	if playerQuery.MaxLV != nil {
+		query += " AND L.LV <= ?"
+		args = append(args, *playerQuery.MaxLV)
+	}
+
+	if playerQuery.NamePrefix != "" {
+		query += " AND P.Name LIKE ?"
+		args = append(args, escapeLike(playerQuery.NamePrefix)+"%")
+	}
+
+	// Keyset pagination, continue after the (sort column, ID) of the cursor row.
+	// A cursor row which is not listed gives an empty page, like MemoryStore.
+	if playerQuery.AfterID != 0 {
+		anchor := "AP.ID = ?"
+		if !playerQuery.IncludeDeleted {
+			anchor += " AND AP.DeletedAt IS NULL"
+		}
+		query += fmt.Sprintf(`
+			AND (%s, P.ID) %s (
+				SELECT %s, AP.ID
+				FROM Player AP
+				INNER JOIN Level AL ON AP.LevelID = AL.ID
+				WHERE %s
+			)`, columns[0], comparison, columns[1], anchor)
+		args = append(args, playerQuery.AfterID)
+	}
+
+	query += fmt.Sprintf(" ORDER BY %s %s, P.ID %s LIMIT ?", columns[0], direction, direction)
+	args = append(args, limit+1)
+
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error querying database with GetPlayersData: %w", err)
 	}
@@ -27,52 +96,63 @@ func GetPlayersData(db *sql.DB) ([]models.PlayerRank, error) {
 	var playerRanks []models.PlayerRank
 	for rows.Next() {
 		var playerRank models.PlayerRank
-		err := rows.Scan(
-			&playerRank.ID,
-			&playerRank.Name,
-			&playerRank.LV,
-		)
+		err := scanPlayerProfile(rows, &playerRank)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning row with GetPlayersData: %w", err)
 		}
 		playerRanks = append(playerRanks, playerRank)
 	}
-	return playerRanks, nil
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows with GetPlayersData: %w", err)
+	}
+
+	return newPage(playerRanks, limit, func(p models.PlayerRank) int { return p.ID }), nil
 }
 
-func AddPlayer(db *sql.DB, name string, lv int) (int, error) {
-	result, err := db.Exec(`
-		INSERT INTO Player (Name, LevelID) 
+func AddPlayer(ctx context.Context, db *sql.DB, name string, lv int) (int, error) {
+	ctx, span := tracing.Start(ctx, "databases.AddPlayer")
+	defer span.End()
+
+	createdAt := auditTime()
+	result, err := db.ExecContext(ctx, `
+		INSERT INTO Player (Name, LevelID, CreatedAt, UpdatedAt) 
 		SELECT 
-		?, ID 
+		?, ID, ?, ? 
 		FROM Level 
 		WHERE LV = ?
-	`, name, lv)
+	`, name, createdAt, createdAt, lv)
 	if err != nil {
 		return 0, fmt.Errorf("error querying database with AddPlayer: %w", err)
 	}
 
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("error querying database with AddPlayer: level %d not found", lv)
+	}
+
 	id, _ := result.LastInsertId()
 	return int(id), nil
 }
 
-func GetPlayer(db *sql.DB, id int) (*models.PlayerRank, error) {
+func GetPlayer(ctx context.Context, db *sql.DB, id int) (*models.PlayerRank, error) {
+	ctx, span := tracing.Start(ctx, "databases.GetPlayer")
+	defer span.End()
+
 	var playerRank models.PlayerRank
-	err := db.QueryRow(`
+	err := scanPlayerProfile(db.QueryRowContext(ctx, `
 		SELECT 
 		P.ID as ID, 
 		P.Name as Name,
-		L.LV as LV
+		L.LV as LV,
+		P.XP as XP,
+	`+playerProfileColumns+`
 		FROM Player P
 		INNER JOIN 
 		Level L 
 		ON P.LevelID = L.ID
-		WHERE P.ID = ?
-	`, id).Scan(
-		&playerRank.ID,
-		&playerRank.Name,
-		&playerRank.LV,
-	)
+		WHERE P.ID = ? AND P.DeletedAt IS NULL
+	`, id), &playerRank)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("error querying database with GetPlayer: %w", err)
 	} else if err != nil {
@@ -81,13 +161,49 @@ func GetPlayer(db *sql.DB, id int) (*models.PlayerRank, error) {
 	return &playerRank, err
 }
 
-func UpdatePlayer(db *sql.DB, playerRank models.PlayerRank) error {
-	query := "UPDATE players SET"
+func UpdatePlayer(ctx context.Context, db *sql.DB, playerRank models.PlayerRank, actor string) error {
+	ctx, span := tracing.Start(ctx, "databases.UpdatePlayer")
+	defer span.End()
+
+	if playerRank.LV == 0 && playerRank.Name == "" {
+		return fmt.Errorf("no fields update for player with id: %d", playerRank.ID)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction with UpdatePlayer: %w", err)
+	}
+	defer tx.Rollback()
+
+	var before models.PlayerRank
+	err = tx.QueryRowContext(ctx, `
+		SELECT 
+		P.Name as Name,
+		L.LV as LV
+		FROM Player P
+		INNER JOIN 
+		Level L 
+		ON P.LevelID = L.ID
+		WHERE P.ID = ? AND P.DeletedAt IS NULL
+		FOR UPDATE
+	`, playerRank.ID).Scan(
+		&before.Name,
+		&before.LV,
+	)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("no rows were updated, player with id %d may not exist: %w", playerRank.ID, err)
+	} else if err != nil {
+		return fmt.Errorf("error querying database with UpdatePlayer: %w", err)
+	}
+
+	before.ID = playerRank.ID
+	after := before
+	updates := []string{}
 	args := []interface{}{}
 
-	if playerRank.LV != 0 {
+	if playerRank.LV != 0 && playerRank.LV != before.LV {
 		var levelID int
-		err := db.QueryRow(`
+		err := tx.QueryRowContext(ctx, `
 			SELECT 
 			ID
 			FROM Level 
@@ -95,57 +211,233 @@ func UpdatePlayer(db *sql.DB, playerRank models.PlayerRank) error {
 		`, playerRank.LV).Scan(
 			&levelID,
 		)
-		if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("error updating player with id %d to lv %d: %w", playerRank.ID, playerRank.LV, ErrLevelNotFound)
+		} else if err != nil {
 			return fmt.Errorf("error querying database with UpdatePlayer: %w", err)
 		}
-		query += " LevelID = ?"
+		updates = append(updates, "LevelID = ?")
 		args = append(args, levelID)
+		after.LV = playerRank.LV
 	}
 
-	if playerRank.Name != "" {
-		if len(args) > 0 {
-			query += ","
-		}
-		query += " Name = ?"
+	if playerRank.Name != "" && playerRank.Name != before.Name {
+		updates = append(updates, "Name = ?")
 		args = append(args, playerRank.Name)
+		after.Name = playerRank.Name
 	}
 
-	if len(args) == 0 {
-		return fmt.Errorf("no fields update for player with id: %d", playerRank.ID)
+	// Nothing differs from the stored player
+	if len(updates) == 0 {
+		return nil
 	}
 
-	query += " WHERE ID = ?"
+	updates = append(updates, "UpdatedAt = ?", "Version = Version + 1")
+	args = append(args, auditTime())
+
+	query := "UPDATE Player SET " + strings.Join(updates, ", ") + " WHERE ID = ?"
 	args = append(args, playerRank.ID)
 
 	// Execute the update query
-	result, err := db.Exec(query, args...)
+	_, err = tx.ExecContext(ctx, query, args...)
 	if err != nil {
 		return fmt.Errorf("error updating player: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	if err = insertPlayerAudits(ctx, tx, playerRankAudits(before, after, actor)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction with UpdatePlayer: %w", err)
+	}
+	return nil
+}
+
+// DeletePlayer soft deletes a player by setting its DeletedAt, the row is kept
+// so rooms and challenges referencing the player stay valid and it can be restored.
+// The rooms leave out deleted players, a restored player is back in the rooms it joined.
+func DeletePlayer(ctx context.Context, db *sql.DB, id int, actor string) error {
+	ctx, span := tracing.Start(ctx, "databases.DeletePlayer")
+	defer span.End()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction with DeletePlayer: %w", err)
+	}
+	defer tx.Rollback()
+
+	deletedAt := auditTime()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE Player 
+		SET DeletedAt = ?, UpdatedAt = ?, Version = Version + 1 
+		WHERE ID = ? AND DeletedAt IS NULL
+	`, deletedAt, deletedAt, id)
 	if err != nil {
-		return fmt.Errorf("error getting rows affected: %w", err)
+		return fmt.Errorf("error querying database with DeletePlayer: %w", err)
 	}
+
+	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("no rows were updated, player with id %d may not exist", playerRank.ID)
+		return fmt.Errorf("no rows were deleted, player with id %d may not exist: %w", id, sql.ErrNoRows)
 	}
 
+	err = insertPlayerAudits(ctx, tx, []models.PlayerAudit{{
+		PlayerID:  id,
+		Actor:     actor,
+		Field:     "deleted_at",
+		NewValue:  formatAuditTime(&deletedAt),
+		ChangedAt: deletedAt,
+	}})
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction with DeletePlayer: %w", err)
+	}
 	return nil
 }
 
-func DeletePlayer(db *sql.DB, id int) error {
-	result, err := db.Exec(`
-		DELETE FROM players 
+// RestorePlayer clears the DeletedAt of a soft deleted player.
+func RestorePlayer(ctx context.Context, db *sql.DB, id int, actor string) error {
+	ctx, span := tracing.Start(ctx, "databases.RestorePlayer")
+	defer span.End()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction with RestorePlayer: %w", err)
+	}
+	defer tx.Rollback()
+
+	var deletedAt *time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT 
+		DeletedAt 
+		FROM Player 
+		WHERE ID = ? 
+		FOR UPDATE
+	`, id).Scan(
+		&deletedAt,
+	)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("error querying database with RestorePlayer: %w", err)
+	} else if err != nil {
+		return fmt.Errorf("error scanning row with RestorePlayer: %w", err)
+	}
+	if deletedAt == nil {
+		return fmt.Errorf("error restoring player with id %d: %w", id, ErrPlayerNotDeleted)
+	}
+
+	restoredAt := auditTime()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE Player 
+		SET DeletedAt = NULL, UpdatedAt = ?, Version = Version + 1 
 		WHERE ID = ?
-	`, id)
+	`, restoredAt, id)
 	if err != nil {
-		return fmt.Errorf("error querying database with DeletePlayer: %w", err)
+		return fmt.Errorf("error querying database with RestorePlayer: %w", err)
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("no rows were deleted, player with id %d may not exist", id)
+	err = insertPlayerAudits(ctx, tx, []models.PlayerAudit{{
+		PlayerID:  id,
+		Actor:     actor,
+		Field:     "deleted_at",
+		OldValue:  formatAuditTime(deletedAt),
+		ChangedAt: restoredAt,
+	}})
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction with RestorePlayer: %w", err)
 	}
 	return nil
 }
+
+// AwardXP adds experience points to a player and, in the same transaction,
+// promotes it to the highest level whose XPThreshold is reached.
+// A player is never demoted by AwardXP.
+func AwardXP(ctx context.Context, db *sql.DB, id int, amount int, actor string) (*models.XPAward, error) {
+	ctx, span := tracing.Start(ctx, "databases.AwardXP")
+	defer span.End()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction with AwardXP: %w", err)
+	}
+	defer tx.Rollback()
+
+	var award models.XPAward
+	err = tx.QueryRowContext(ctx, `
+		SELECT 
+		P.ID as ID, 
+		P.Name as Name,
+		L.LV as LV,
+		P.XP as XP
+		FROM Player P
+		INNER JOIN 
+		Level L 
+		ON P.LevelID = L.ID
+		WHERE P.ID = ? AND P.DeletedAt IS NULL
+		FOR UPDATE
+	`, id).Scan(
+		&award.Before.ID,
+		&award.Before.Name,
+		&award.Before.LV,
+		&award.Before.XP,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("error querying database with AwardXP: %w", err)
+	} else if err != nil {
+		return nil, fmt.Errorf("error scanning row with AwardXP: %w", err)
+	}
+
+	award.After = award.Before
+	award.After.XP += amount
+
+	var levelID, lv int
+	err = tx.QueryRowContext(ctx, `
+		SELECT 
+		ID, LV 
+		FROM Level 
+		WHERE XPThreshold <= ? AND LV > ? 
+		ORDER BY LV DESC 
+		LIMIT 1
+	`, award.After.XP, award.Before.LV).Scan(
+		&levelID,
+		&lv,
+	)
+
+	switch {
+	case err == sql.ErrNoRows:
+		_, err = tx.ExecContext(ctx, `
+			UPDATE Player 
+			SET XP = ?, UpdatedAt = ?, Version = Version + 1 
+			WHERE ID = ?
+		`, award.After.XP, auditTime(), id)
+	case err != nil:
+		return nil, fmt.Errorf("error querying database with AwardXP: %w", err)
+	default:
+		award.After.LV = lv
+		_, err = tx.ExecContext(ctx, `
+			UPDATE Player 
+			SET XP = ?, LevelID = ?, UpdatedAt = ?, Version = Version + 1 
+			WHERE ID = ?
+		`, award.After.XP, levelID, auditTime(), id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error updating player with AwardXP: %w", err)
+	}
+
+	err = insertPlayerAudits(ctx, tx, playerRankAudits(award.Before, award.After, actor))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction with AwardXP: %w", err)
+	}
+	return &award, nil
+}
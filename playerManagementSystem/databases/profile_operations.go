@@ -0,0 +1,233 @@
+package databases
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
+)
+
+// playerProfileColumns are the profile columns of P selected after ID, Name, LV and XP,
+// scanned by scanPlayerProfile.
+const playerProfileColumns = `
+	P.DisplayName as DisplayName,
+	P.AvatarURL as AvatarURL,
+	P.Country as Country,
+	P.Locale as Locale,
+	P.CreatedAt as CreatedAt,
+	P.UpdatedAt as UpdatedAt,
+	P.Version as Version,
+	P.DeletedAt as DeletedAt
+`
+
+// Limits of the profile fields, matching the Player columns.
+const (
+	maxDisplayNameLength = 64
+	maxAvatarURLLength   = 2048
+)
+
+var (
+	countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	localePattern  = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+)
+
+// scanPlayerProfile scans a row of ID, Name, LV, XP and playerProfileColumns.
+func scanPlayerProfile(row interface{ Scan(...interface{}) error }, playerRank *models.PlayerRank) error {
+	var createdAt, updatedAt time.Time
+	err := row.Scan(
+		&playerRank.ID,
+		&playerRank.Name,
+		&playerRank.LV,
+		&playerRank.XP,
+		&playerRank.DisplayName,
+		&playerRank.AvatarURL,
+		&playerRank.Country,
+		&playerRank.Locale,
+		&createdAt,
+		&updatedAt,
+		&playerRank.Version,
+		&playerRank.DeletedAt,
+	)
+	if err != nil {
+		return err
+	}
+	playerRank.CreatedAt = &createdAt
+	playerRank.UpdatedAt = &updatedAt
+	return nil
+}
+
+// applyPlayerPatch applies a JSON Merge Patch (RFC 7396) to a player.
+// Only name, lv and the profile fields can be patched, a null removes a
+// profile field while name and lv cannot be removed.
+func applyPlayerPatch(playerRank models.PlayerRank, patch []byte) (models.PlayerRank, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &fields); err != nil || fields == nil {
+		return playerRank, fmt.Errorf("%w: patch must be a JSON object", ErrInvalidPatch)
+	}
+
+	for field, raw := range fields {
+		isNull := bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+		var target **string
+		switch field {
+		case "name":
+			if isNull || json.Unmarshal(raw, &playerRank.Name) != nil {
+				return playerRank, fmt.Errorf("%w: name must be a string", ErrInvalidPatch)
+			}
+			continue
+		case "lv":
+			if isNull || json.Unmarshal(raw, &playerRank.LV) != nil {
+				return playerRank, fmt.Errorf("%w: lv must be an integer", ErrInvalidPatch)
+			}
+			continue
+		case "display_name":
+			target = &playerRank.DisplayName
+		case "avatar_url":
+			target = &playerRank.AvatarURL
+		case "country":
+			target = &playerRank.Country
+		case "locale":
+			target = &playerRank.Locale
+		default:
+			return playerRank, fmt.Errorf("%w: field %q cannot be patched", ErrInvalidPatch, field)
+		}
+
+		if isNull {
+			*target = nil
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return playerRank, fmt.Errorf("%w: %s must be a string or null", ErrInvalidPatch, field)
+		}
+		*target = &value
+	}
+
+	playerRank.Name = strings.TrimSpace(playerRank.Name)
+	if playerRank.Country != nil {
+		country := strings.ToUpper(*playerRank.Country)
+		playerRank.Country = &country
+	}
+	if err := validatePlayerProfile(playerRank); err != nil {
+		return playerRank, err
+	}
+	return playerRank, nil
+}
+
+// validatePlayerProfile checks the patchable fields of a player.
+func validatePlayerProfile(playerRank models.PlayerRank) error {
+	if playerRank.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidPatch)
+	}
+	if playerRank.DisplayName != nil {
+		length := utf8.RuneCountInString(*playerRank.DisplayName)
+		if strings.TrimSpace(*playerRank.DisplayName) == "" || length > maxDisplayNameLength {
+			return fmt.Errorf("%w: display_name must have 1 to %d characters", ErrInvalidPatch, maxDisplayNameLength)
+		}
+	}
+	if playerRank.AvatarURL != nil {
+		u, err := url.Parse(*playerRank.AvatarURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(*playerRank.AvatarURL) > maxAvatarURLLength {
+			return fmt.Errorf("%w: avatar_url must be an absolute http or https URL", ErrInvalidPatch)
+		}
+	}
+	if playerRank.Country != nil && !countryPattern.MatchString(*playerRank.Country) {
+		return fmt.Errorf("%w: country must be an ISO 3166-1 alpha-2 code", ErrInvalidPatch)
+	}
+	if playerRank.Locale != nil && !localePattern.MatchString(*playerRank.Locale) {
+		return fmt.Errorf("%w: locale must be a BCP 47 language tag", ErrInvalidPatch)
+	}
+	return nil
+}
+
+// PatchPlayer applies a JSON Merge Patch to a player and returns the patched player.
+// When version is not 0 the player must still be at that version, otherwise
+// ErrVersionMismatch is returned. Every change increments the version.
+func PatchPlayer(ctx context.Context, db *sql.DB, id int, patch []byte, version int, actor string) (*models.PlayerRank, error) {
+	ctx, span := tracing.Start(ctx, "databases.PatchPlayer")
+	defer span.End()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction with PatchPlayer: %w", err)
+	}
+	defer tx.Rollback()
+
+	var before models.PlayerRank
+	err = scanPlayerProfile(tx.QueryRowContext(ctx, `
+		SELECT 
+		P.ID as ID, 
+		P.Name as Name,
+		L.LV as LV,
+		P.XP as XP,
+	`+playerProfileColumns+`
+		FROM Player P
+		INNER JOIN 
+		Level L 
+		ON P.LevelID = L.ID
+		WHERE P.ID = ? AND P.DeletedAt IS NULL
+		FOR UPDATE
+	`, id), &before)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("error querying database with PatchPlayer: %w", err)
+	} else if err != nil {
+		return nil, fmt.Errorf("error scanning row with PatchPlayer: %w", err)
+	}
+	if version != 0 && version != before.Version {
+		return nil, fmt.Errorf("error patching player with id %d at version %d: %w", id, before.Version, ErrVersionMismatch)
+	}
+
+	after, err := applyPlayerPatch(before, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	audits := playerRankAudits(before, after, actor)
+	if len(audits) == 0 {
+		return &before, nil
+	}
+
+	var levelID int
+	err = tx.QueryRowContext(ctx, `
+		SELECT 
+		ID
+		FROM Level 
+		WHERE LV = ?
+	`, after.LV).Scan(
+		&levelID,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: level %d not found", ErrInvalidPatch, after.LV)
+	} else if err != nil {
+		return nil, fmt.Errorf("error querying database with PatchPlayer: %w", err)
+	}
+
+	updatedAt := auditTime()
+	after.Version++
+	after.UpdatedAt = &updatedAt
+	_, err = tx.ExecContext(ctx, `
+		UPDATE Player 
+		SET Name = ?, LevelID = ?, DisplayName = ?, AvatarURL = ?, Country = ?, Locale = ?, UpdatedAt = ?, Version = ? 
+		WHERE ID = ?
+	`, after.Name, levelID, after.DisplayName, after.AvatarURL, after.Country, after.Locale, updatedAt, after.Version, id)
+	if err != nil {
+		return nil, fmt.Errorf("error updating player with PatchPlayer: %w", err)
+	}
+
+	if err = insertPlayerAudits(ctx, tx, audits); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing transaction with PatchPlayer: %w", err)
+	}
+	return &after, nil
+}
@@ -0,0 +1,117 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
+	"sort"
+)
+
+// uniqueRoles returns the roles sorted and without duplicates.
+func uniqueRoles(roles []string) []string {
+	unique := []string{}
+	seen := make(map[string]bool)
+	for _, role := range roles {
+		if !seen[role] {
+			seen[role] = true
+			unique = append(unique, role)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// activePlayerExists returns sql.ErrNoRows when the player does not exist or is soft deleted.
+func activePlayerExists(ctx context.Context, db queryRower, playerID int) error {
+	var id int
+	return db.QueryRowContext(ctx, `
+		SELECT 
+		ID 
+		FROM Player 
+		WHERE ID = ? AND DeletedAt IS NULL
+	`, playerID).Scan(&id)
+}
+
+// GetRoles returns the roles granted to an active player, sorted by name.
+func GetRoles(ctx context.Context, db *sql.DB, playerID int) ([]string, error) {
+	ctx, span := tracing.Start(ctx, "databases.GetRoles")
+	defer span.End()
+
+	err := activePlayerExists(ctx, db, playerID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("error querying database with GetRoles: %w", err)
+	} else if err != nil {
+		return nil, fmt.Errorf("error scanning row with GetRoles: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT 
+		Role 
+		FROM PlayerRole 
+		WHERE PlayerID = ? 
+		ORDER BY Role
+	`, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying database with GetRoles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []string{}
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, fmt.Errorf("error scanning row with GetRoles: %w", err)
+		}
+		roles = append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows with GetRoles: %w", err)
+	}
+	return roles, nil
+}
+
+// SetRoles replaces the roles granted to an active player.
+func SetRoles(ctx context.Context, db *sql.DB, playerID int, roles []string) error {
+	ctx, span := tracing.Start(ctx, "databases.SetRoles")
+	defer span.End()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction with SetRoles: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = activePlayerExists(ctx, tx, playerID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("error querying database with SetRoles: %w", err)
+	} else if err != nil {
+		return fmt.Errorf("error scanning row with SetRoles: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM PlayerRole 
+		WHERE PlayerID = ? 
+	`, playerID)
+	if err != nil {
+		return fmt.Errorf("error querying database with SetRoles: %w", err)
+	}
+	for _, role := range uniqueRoles(roles) {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO PlayerRole (PlayerID, Role) 
+			VALUES (?, ?)
+		`, playerID, role)
+		if err != nil {
+			return fmt.Errorf("error inserting role %q with SetRoles: %w", role, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction with SetRoles: %w", err)
+	}
+	return nil
+}
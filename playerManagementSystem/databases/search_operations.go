@@ -0,0 +1,216 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/tracing"
+)
+
+const (
+	// DefaultSearchLimit is the number of results returned when the query has no limit.
+	DefaultSearchLimit = 20
+	// searchCandidates is the number of rows read from each index before ranking.
+	searchCandidates = 200
+)
+
+// maxEdits is the edit distance tolerated for a query, short queries must be exact.
+func maxEdits(query string) int {
+	switch n := utf8.RuneCountInString(query); {
+	case n < 3:
+		return 0
+	case n < 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// matchName scores how well a player name matches a search query, case-insensitive.
+// Exact matches score 1, then prefix, substring and fuzzy matches, and within
+// each kind names closer in length to the query score higher.
+func matchName(name, query string) (string, float64, bool) {
+	name, query = strings.ToLower(name), strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return "", 0, false
+	}
+	nameLength, queryLength := utf8.RuneCountInString(name), utf8.RuneCountInString(query)
+	closeness := 0.1 * float64(queryLength) / float64(maxInt(nameLength, queryLength))
+
+	switch {
+	case name == query:
+		return models.MatchExact, 1, true
+	case strings.HasPrefix(name, query):
+		return models.MatchPrefix, 0.8 + closeness, true
+	case strings.Contains(name, query):
+		return models.MatchSubstring, 0.6 + closeness, true
+	}
+
+	// A typo anywhere in the name, or in the part of the name the query is a prefix of
+	distance := editDistance(query, name)
+	if nameLength > queryLength {
+		if d := editDistance(query, string([]rune(name)[:queryLength])); d < distance {
+			distance = d
+		}
+	}
+	if distance > maxEdits(query) {
+		return "", 0, false
+	}
+	return models.MatchFuzzy, 0.4 - 0.1*float64(distance) + closeness, true
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// rankSearchResults scores the candidates, drops the ones that do not match
+// and returns the best limit results.
+func rankSearchResults(candidates []models.PlayerRank, query string, limit int) []models.PlayerSearchResult {
+	results := []models.PlayerSearchResult{}
+	for _, playerRank := range candidates {
+		match, score, ok := matchName(playerRank.Name, query)
+		if !ok {
+			continue
+		}
+		results = append(results, models.PlayerSearchResult{
+			Match:      match,
+			Score:      math.Round(score*1000) / 1000,
+			PlayerRank: playerRank,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Name != results[j].Name {
+			return results[i].Name < results[j].Name
+		}
+		return results[i].ID < results[j].ID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func searchLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultSearchLimit
+	}
+	return limit
+}
+
+// SearchPlayers finds active players by name. Prefix and substring candidates
+// come from a LIKE on Name, typo candidates from the ngram FULLTEXT index on
+// Name, and both are ranked together by matchName.
+func SearchPlayers(ctx context.Context, db *sql.DB, searchQuery models.PlayerSearchQuery) ([]models.PlayerSearchResult, error) {
+	ctx, span := tracing.Start(ctx, "databases.SearchPlayers")
+	defer span.End()
+
+	query := strings.TrimSpace(searchQuery.Q)
+	selectPlayers := `
+		SELECT 
+		P.ID as ID, 
+		P.Name as Name,
+		L.LV as LV,
+		P.XP as XP,
+	` + playerProfileColumns + `
+		FROM Player P
+		INNER JOIN 
+		Level L 
+		ON P.LevelID = L.ID
+		WHERE P.DeletedAt IS NULL
+	`
+
+	type candidateQuery struct {
+		query string
+		args  []interface{}
+	}
+	searches := []candidateQuery{
+		{
+			selectPlayers + `
+				AND P.Name LIKE ?
+				ORDER BY P.Name LIKE ? DESC, CHAR_LENGTH(P.Name), P.ID
+				LIMIT ?`,
+			[]interface{}{"%" + escapeLike(query) + "%", escapeLike(query) + "%", searchCandidates},
+		},
+	}
+	// The ngram parser indexes pairs of characters, shorter queries cannot typo match
+	if maxEdits(query) > 0 {
+		searches = append(searches, candidateQuery{
+			selectPlayers + `
+				AND MATCH (P.Name) AGAINST (? IN NATURAL LANGUAGE MODE)
+				ORDER BY MATCH (P.Name) AGAINST (? IN NATURAL LANGUAGE MODE) DESC, P.ID
+				LIMIT ?`,
+			[]interface{}{query, query, searchCandidates},
+		})
+	}
+
+	seen := map[int]bool{}
+	var candidates []models.PlayerRank
+	for _, search := range searches {
+		rows, err := db.QueryContext(ctx, search.query, search.args...)
+		if err != nil {
+			return nil, fmt.Errorf("error querying database with SearchPlayers: %w", err)
+		}
+		for rows.Next() {
+			var playerRank models.PlayerRank
+			if err := scanPlayerProfile(rows, &playerRank); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("error scanning row with SearchPlayers: %w", err)
+			}
+			if !seen[playerRank.ID] {
+				seen[playerRank.ID] = true
+				candidates = append(candidates, playerRank)
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error iterating over rows with SearchPlayers: %w", err)
+		}
+	}
+
+	return rankSearchResults(candidates, query, searchLimit(searchQuery.Limit)), nil
+}
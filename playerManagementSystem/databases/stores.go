@@ -0,0 +1,199 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+)
+
+// PlayerStore is the storage used by the players handlers.
+type PlayerStore interface {
+	GetPlayersData(ctx context.Context, playerQuery models.PlayerQuery) (*models.Page[models.PlayerRank], error)
+	AddPlayer(ctx context.Context, name string, lv int) (int, error)
+	GetPlayer(ctx context.Context, id int) (*models.PlayerRank, error)
+	SearchPlayers(ctx context.Context, searchQuery models.PlayerSearchQuery) ([]models.PlayerSearchResult, error)
+	UpdatePlayer(ctx context.Context, playerRank models.PlayerRank, actor string) error
+	PatchPlayer(ctx context.Context, id int, patch []byte, version int, actor string) (*models.PlayerRank, error)
+	DeletePlayer(ctx context.Context, id int, actor string) error
+	RestorePlayer(ctx context.Context, id int, actor string) error
+	GetPlayerAudit(ctx context.Context, id int) ([]models.PlayerAudit, error)
+	GetLeaderboard(ctx context.Context, leaderboardQuery models.LeaderboardQuery) (*models.Page[models.LeaderboardEntry], error)
+	GetPlayerRank(ctx context.Context, id int) (*models.LeaderboardEntry, error)
+	AwardXP(ctx context.Context, id int, amount int, actor string) (*models.XPAward, error)
+	ImportPlayers(ctx context.Context, rows []models.PlayerImportRow) ([]models.PlayerImportResult, error)
+	ExportPlayers(ctx context.Context, fn func(models.PlayerRank) error) error
+}
+
+// LevelStore is the storage used by the levels handlers.
+type LevelStore interface {
+	GetLevelsData(ctx context.Context) ([]models.Level, error)
+	AddLevel(ctx context.Context, level models.Level) (int, error)
+	GetLevel(ctx context.Context, id int) (*models.Level, error)
+	UpdateLevel(ctx context.Context, level models.Level) error
+	DeleteLevel(ctx context.Context, id int, reassignLV *int, actor string) error
+}
+
+// AuthStore is the storage used by the auth handlers.
+type AuthStore interface {
+	Register(ctx context.Context, username string, passwordHash string) (int, error)
+	GetCredential(ctx context.Context, username string) (*models.Credential, error)
+	CreateRefreshToken(ctx context.Context, token models.RefreshToken) error
+	RotateRefreshToken(ctx context.Context, tokenHash string, next *models.RefreshToken) error
+	RevokeRefreshToken(ctx context.Context, tokenHash string) error
+	GetRoles(ctx context.Context, playerID int) ([]string, error)
+	SetRoles(ctx context.Context, playerID int, roles []string) error
+}
+
+// APIKeyStore is the storage used by the API keys handlers and middleware.
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+	RotateAPIKey(ctx context.Context, id int64, next *models.APIKey, overlapUntil time.Time) error
+	VerifyAPIKey(ctx context.Context, keyHash string) (int64, []string, error)
+}
+
+// Store is the full storage of the service, implemented by MySQLStore and MemoryStore.
+type Store interface {
+	PlayerStore
+	LevelStore
+	AuthStore
+	APIKeyStore
+}
+
+// MySQLStore implements Store on top of a MySQL connection.
+type MySQLStore struct {
+	db *sql.DB
+}
+
+func NewMySQLStore(db *sql.DB) *MySQLStore {
+	return &MySQLStore{
+		db: db,
+	}
+}
+
+func (s *MySQLStore) GetPlayersData(ctx context.Context, playerQuery models.PlayerQuery) (*models.Page[models.PlayerRank], error) {
+	return GetPlayersData(ctx, s.db, playerQuery)
+}
+
+func (s *MySQLStore) AddPlayer(ctx context.Context, name string, lv int) (int, error) {
+	return AddPlayer(ctx, s.db, name, lv)
+}
+
+func (s *MySQLStore) GetPlayer(ctx context.Context, id int) (*models.PlayerRank, error) {
+	return GetPlayer(ctx, s.db, id)
+}
+
+func (s *MySQLStore) SearchPlayers(ctx context.Context, searchQuery models.PlayerSearchQuery) ([]models.PlayerSearchResult, error) {
+	return SearchPlayers(ctx, s.db, searchQuery)
+}
+
+func (s *MySQLStore) UpdatePlayer(ctx context.Context, playerRank models.PlayerRank, actor string) error {
+	return UpdatePlayer(ctx, s.db, playerRank, actor)
+}
+
+func (s *MySQLStore) PatchPlayer(ctx context.Context, id int, patch []byte, version int, actor string) (*models.PlayerRank, error) {
+	return PatchPlayer(ctx, s.db, id, patch, version, actor)
+}
+
+func (s *MySQLStore) DeletePlayer(ctx context.Context, id int, actor string) error {
+	return DeletePlayer(ctx, s.db, id, actor)
+}
+
+func (s *MySQLStore) RestorePlayer(ctx context.Context, id int, actor string) error {
+	return RestorePlayer(ctx, s.db, id, actor)
+}
+
+func (s *MySQLStore) GetPlayerAudit(ctx context.Context, id int) ([]models.PlayerAudit, error) {
+	return GetPlayerAudit(ctx, s.db, id)
+}
+
+func (s *MySQLStore) GetLeaderboard(ctx context.Context, leaderboardQuery models.LeaderboardQuery) (*models.Page[models.LeaderboardEntry], error) {
+	return GetLeaderboard(ctx, s.db, leaderboardQuery)
+}
+
+func (s *MySQLStore) GetPlayerRank(ctx context.Context, id int) (*models.LeaderboardEntry, error) {
+	return GetPlayerRank(ctx, s.db, id)
+}
+
+func (s *MySQLStore) AwardXP(ctx context.Context, id int, amount int, actor string) (*models.XPAward, error) {
+	return AwardXP(ctx, s.db, id, amount, actor)
+}
+
+func (s *MySQLStore) ImportPlayers(ctx context.Context, rows []models.PlayerImportRow) ([]models.PlayerImportResult, error) {
+	return ImportPlayers(ctx, s.db, rows)
+}
+
+func (s *MySQLStore) ExportPlayers(ctx context.Context, fn func(models.PlayerRank) error) error {
+	return ExportPlayers(ctx, s.db, fn)
+}
+
+func (s *MySQLStore) Register(ctx context.Context, username string, passwordHash string) (int, error) {
+	return Register(ctx, s.db, username, passwordHash)
+}
+
+func (s *MySQLStore) GetCredential(ctx context.Context, username string) (*models.Credential, error) {
+	return GetCredential(ctx, s.db, username)
+}
+
+func (s *MySQLStore) CreateRefreshToken(ctx context.Context, token models.RefreshToken) error {
+	return CreateRefreshToken(ctx, s.db, token)
+}
+
+func (s *MySQLStore) RotateRefreshToken(ctx context.Context, tokenHash string, next *models.RefreshToken) error {
+	return RotateRefreshToken(ctx, s.db, tokenHash, next)
+}
+
+func (s *MySQLStore) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	return RevokeRefreshToken(ctx, s.db, tokenHash)
+}
+
+func (s *MySQLStore) GetRoles(ctx context.Context, playerID int) ([]string, error) {
+	return GetRoles(ctx, s.db, playerID)
+}
+
+func (s *MySQLStore) SetRoles(ctx context.Context, playerID int, roles []string) error {
+	return SetRoles(ctx, s.db, playerID, roles)
+}
+
+func (s *MySQLStore) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	return CreateAPIKey(ctx, s.db, key)
+}
+
+func (s *MySQLStore) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return ListAPIKeys(ctx, s.db)
+}
+
+func (s *MySQLStore) RevokeAPIKey(ctx context.Context, id int64) error {
+	return RevokeAPIKey(ctx, s.db, id)
+}
+
+func (s *MySQLStore) RotateAPIKey(ctx context.Context, id int64, next *models.APIKey, overlapUntil time.Time) error {
+	return RotateAPIKey(ctx, s.db, id, next, overlapUntil)
+}
+
+func (s *MySQLStore) VerifyAPIKey(ctx context.Context, keyHash string) (int64, []string, error) {
+	return VerifyAPIKey(ctx, s.db, keyHash)
+}
+
+func (s *MySQLStore) GetLevelsData(ctx context.Context) ([]models.Level, error) {
+	return GetLevelsData(ctx, s.db)
+}
+
+func (s *MySQLStore) AddLevel(ctx context.Context, level models.Level) (int, error) {
+	return AddLevel(ctx, s.db, level)
+}
+
+func (s *MySQLStore) GetLevel(ctx context.Context, id int) (*models.Level, error) {
+	return GetLevel(ctx, s.db, id)
+}
+
+func (s *MySQLStore) UpdateLevel(ctx context.Context, level models.Level) error {
+	return UpdateLevel(ctx, s.db, level)
+}
+
+func (s *MySQLStore) DeleteLevel(ctx context.Context, id int, reassignLV *int, actor string) error {
+	return DeleteLevel(ctx, s.db, id, reassignLV, actor)
+}
@@ -0,0 +1,61 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- MySQL Script generated by MySQL Workbench
+-- Sat Jul  27 16:09:21 2024
+-- Model: New Model    Version: 1.0
+-- MySQL Workbench Forward Engineering;
+
+SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0;
+SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0;
+SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_ZERO_IN_DATE,NO_ZERO_DATE,ERROR_FOR_DIVISION_BY_ZERO,NO_ENGINE_SUBSTITUTION';
+
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+CREATE SCHEMA IF NOT EXISTS `SpinnrTechnology` DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci ;
+USE `SpinnrTechnology` ;
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`APIKey`
+-- Keys of the service-to-service calls, every service verifies X-API-Key against it.
+-- Scopes are the granted permissions separated by spaces.
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`APIKey` (
+    `ID` BIGINT AUTO_INCREMENT PRIMARY KEY,
+    `Name` VARCHAR(64) NOT NULL,
+    `Prefix` VARCHAR(16) NOT NULL,
+    `KeyHash` CHAR(64) NOT NULL,
+    `Scopes` VARCHAR(1024) NOT NULL,
+    `RotatedFromID` BIGINT NULL DEFAULT NULL,
+    `CreatedAt` DATETIME NOT NULL,
+    `ExpiresAt` DATETIME NULL DEFAULT NULL,
+    `RevokedAt` DATETIME NULL DEFAULT NULL,
+    `LastUsedAt` DATETIME NULL DEFAULT NULL,
+    UNIQUE KEY `UQ_APIKey_KeyHash` (`KeyHash`),
+    FOREIGN KEY (`RotatedFromID`) REFERENCES `APIKey`(`ID`))
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+
+SET SQL_MODE=@OLD_SQL_MODE;
+SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;
+SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS;
+
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`APIKey`
+-- -----------------------------------------------------
+DROP TABLE IF EXISTS `SpinnrTechnology`.`APIKey` ;
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+DROP SCHEMA IF EXISTS `SpinnrTechnology` ;
\ No newline at end of file
@@ -26,11 +26,20 @@ USE `SpinnrTechnology` ;
 CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`Level` (
     `ID` INT AUTO_INCREMENT PRIMARY KEY,
     `Name` VARCHAR(255) NOT NULL,
-    `LV` INT NOT NULL)
+    `LV` INT NOT NULL,
+    `XPThreshold` INT NOT NULL DEFAULT 0,
+    UNIQUE KEY `UQ_Level_LV` (`LV`))
 ENGINE = InnoDB
 DEFAULT CHARACTER SET = utf8mb4
 COLLATE = utf8mb4_0900_ai_ci;
 
+-- -----------------------------------------------------
+-- Insert the starting level, players register at it before any level is managed
+-- -----------------------------------------------------
+INSERT INTO `SpinnrTechnology`.`Level` (Name, LV, XPThreshold)
+SELECT 'Beginner', 1, 0
+WHERE NOT EXISTS (SELECT 1 FROM `SpinnrTechnology`.`Level` WHERE LV = 1);
+
 
 SET SQL_MODE=@OLD_SQL_MODE;
 SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;
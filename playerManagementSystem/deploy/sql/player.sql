@@ -27,6 +27,16 @@ CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`Player` (
     `ID` INT AUTO_INCREMENT PRIMARY KEY,
     `Name` VARCHAR(255) NOT NULL,
     `LevelID` INT NOT NULL,
+    `XP` INT NOT NULL DEFAULT 0,
+    `DisplayName` VARCHAR(64) NULL DEFAULT NULL,
+    `AvatarURL` VARCHAR(2048) NULL DEFAULT NULL,
+    `Country` CHAR(2) NULL DEFAULT NULL,
+    `Locale` VARCHAR(35) NULL DEFAULT NULL,
+    `CreatedAt` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `UpdatedAt` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `Version` INT NOT NULL DEFAULT 1,
+    `DeletedAt` DATETIME NULL DEFAULT NULL,
+    FULLTEXT KEY `FT_Player_Name` (`Name`) WITH PARSER ngram,
     FOREIGN KEY (`LevelID`) REFERENCES `Level`(`ID`)
 )
 ENGINE = InnoDB
@@ -0,0 +1,56 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- MySQL Script generated by MySQL Workbench
+-- Sat Jul  27 16:09:21 2024
+-- Model: New Model    Version: 1.0
+-- MySQL Workbench Forward Engineering;
+
+SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0;
+SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0;
+SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_ZERO_IN_DATE,NO_ZERO_DATE,ERROR_FOR_DIVISION_BY_ZERO,NO_ENGINE_SUBSTITUTION';
+
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+CREATE SCHEMA IF NOT EXISTS `SpinnrTechnology` DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci ;
+USE `SpinnrTechnology` ;
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`PlayerAudit`
+-- -----------------------------------------------------
+CREATE TABLE IF NOT EXISTS `SpinnrTechnology`.`PlayerAudit` (
+    `ID` BIGINT AUTO_INCREMENT PRIMARY KEY,
+    `PlayerID` INT NOT NULL,
+    `Actor` VARCHAR(255) NOT NULL,
+    `Field` VARCHAR(64) NOT NULL,
+    `OldValue` TEXT NOT NULL,
+    `NewValue` TEXT NOT NULL,
+    `ChangedAt` DATETIME NOT NULL,
+    INDEX `IX_PlayerAudit_PlayerID` (`PlayerID`),
+    FOREIGN KEY (`PlayerID`) REFERENCES `Player`(`ID`))
+ENGINE = InnoDB
+DEFAULT CHARACTER SET = utf8mb4
+COLLATE = utf8mb4_0900_ai_ci;
+
+
+SET SQL_MODE=@OLD_SQL_MODE;
+SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;
+SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS;
+
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`PlayerAudit`
+-- -----------------------------------------------------
+DROP TABLE IF EXISTS `SpinnrTechnology`.`PlayerAudit` ;
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+DROP SCHEMA IF EXISTS `SpinnrTechnology` ;
\ No newline at end of file
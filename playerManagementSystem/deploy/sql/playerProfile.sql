@@ -0,0 +1,56 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- MySQL Script generated by MySQL Workbench
+-- Sat Jul  27 16:09:21 2024
+-- Model: New Model    Version: 1.0
+-- MySQL Workbench Forward Engineering;
+
+SET @OLD_UNIQUE_CHECKS=@@UNIQUE_CHECKS, UNIQUE_CHECKS=0;
+SET @OLD_FOREIGN_KEY_CHECKS=@@FOREIGN_KEY_CHECKS, FOREIGN_KEY_CHECKS=0;
+SET @OLD_SQL_MODE=@@SQL_MODE, SQL_MODE='ONLY_FULL_GROUP_BY,STRICT_TRANS_TABLES,NO_ZERO_IN_DATE,NO_ZERO_DATE,ERROR_FOR_DIVISION_BY_ZERO,NO_ENGINE_SUBSTITUTION';
+
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+
+-- -----------------------------------------------------
+-- Schema SpinnrTechnology
+-- -----------------------------------------------------
+CREATE SCHEMA IF NOT EXISTS `SpinnrTechnology` DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci ;
+USE `SpinnrTechnology` ;
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`Player`
+-- Profile fields and version of the players, added to the players created
+-- before it. Their CreatedAt and UpdatedAt are the time of the migration.
+-- -----------------------------------------------------
+ALTER TABLE `SpinnrTechnology`.`Player`
+    ADD COLUMN `DisplayName` VARCHAR(64) NULL DEFAULT NULL,
+    ADD COLUMN `AvatarURL` VARCHAR(2048) NULL DEFAULT NULL,
+    ADD COLUMN `Country` CHAR(2) NULL DEFAULT NULL,
+    ADD COLUMN `Locale` VARCHAR(35) NULL DEFAULT NULL,
+    ADD COLUMN `CreatedAt` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN `UpdatedAt` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN `Version` INT NOT NULL DEFAULT 1;
+
+
+SET SQL_MODE=@OLD_SQL_MODE;
+SET FOREIGN_KEY_CHECKS=@OLD_FOREIGN_KEY_CHECKS;
+SET UNIQUE_CHECKS=@OLD_UNIQUE_CHECKS;
+
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+-- -----------------------------------------------------
+-- Table `SpinnrTechnology`.`Player`
+-- -----------------------------------------------------
+ALTER TABLE `SpinnrTechnology`.`Player`
+    DROP COLUMN `DisplayName`,
+    DROP COLUMN `AvatarURL`,
+    DROP COLUMN `Country`,
+    DROP COLUMN `Locale`,
+    DROP COLUMN `CreatedAt`,
+    DROP COLUMN `UpdatedAt`,
+    DROP COLUMN `Version`;
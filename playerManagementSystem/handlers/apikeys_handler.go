@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/auth"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/databases"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newAPIKey generates the key of an API key expiring at expiresAt, nil for never.
+func newAPIKey(expiresAt *time.Time) (*models.APIKey, string, error) {
+	key, prefix, keyHash, err := auth.NewAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+	return &models.APIKey{
+		Prefix:    prefix,
+		KeyHash:   keyHash,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		ExpiresAt: expiresAt,
+	}, key, nil
+}
+
+// @Summary      Create an API key
+// @Description  Create an API key for another service. The scopes are the permissions it is granted, such as rooms:update. The key is only returned in this response, send it in the X-API-Key header.
+// @Tags         api_keys
+// @Accept       json
+// @Produce      json
+// @Param        api_key  body  models.CreateAPIKeyRequest  true  "Name, scopes and expiry of the API key"
+// @Success      201  {object}  models.APIKeyResponse  "API key created"
+// @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid input"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission api_keys:manage"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api_keys [post]
+func CreateAPIKey(c *gin.Context, store databases.APIKeyStore) {
+	var request models.CreateAPIKeyRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+
+	apiKey, key, err := newAPIKey(request.ExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	apiKey.Name = request.Name
+	apiKey.Scopes = request.Scopes
+	if err := store.CreateAPIKey(c.Request.Context(), apiKey); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, models.APIKeyResponse{Key: key, APIKey: *apiKey})
+}
+
+// @Summary      List API keys
+// @Description  List every API key, newest first, including revoked and expired ones. The keys themselves are never returned, only their prefix.
+// @Tags         api_keys
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  []models.APIKey  "API keys"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission api_keys:manage"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api_keys [get]
+func ListAPIKeys(c *gin.Context, store databases.APIKeyStore) {
+	keys, err := store.ListAPIKeys(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// @Summary      Revoke an API key
+// @Description  Stop an API key from working immediately.
+// @Tags         api_keys
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "API key ID"
+// @Success      200  {object}  models.SuccessResponse  "API key revoked"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid ID supplied"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission api_keys:manage"
+// @Failure      404  {object}  models.ErrorResponse  "API key not found"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api_keys/{id} [delete]
+func RevokeAPIKey(c *gin.Context, store databases.APIKeyStore) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid api key id"))
+		return
+	}
+	err = store.RevokeAPIKey(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(c, "api key not found"))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse{})
+}
+
+// @Summary      Rotate an API key
+// @Description  Replace an API key with a new one with the same name and scopes. The old key keeps working for the overlap, 24h by default, so the calling service can switch over. The new key is only returned in this response.
+// @Tags         api_keys
+// @Accept       json
+// @Produce      json
+// @Param        id      path  int                         true   "API key ID"
+// @Param        rotate  body  models.RotateAPIKeyRequest  false  "Overlap of the old key and expiry of the new key"
+// @Success      201  {object}  models.APIKeyResponse  "New API key"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid ID or overlap supplied"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission api_keys:manage"
+// @Failure      404  {object}  models.ErrorResponse  "API key not found"
+// @Failure      409  {object}  models.ErrorResponse  "API key is revoked or expired"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /api_keys/{id}/rotate [post]
+func RotateAPIKey(c *gin.Context, store databases.APIKeyStore) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid api key id"))
+		return
+	}
+	var request models.RotateAPIKeyRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+			return
+		}
+	}
+	overlap := auth.DefaultAPIKeyOverlap
+	if request.Overlap != "" {
+		overlap, err = time.ParseDuration(request.Overlap)
+		if err != nil || overlap < 0 {
+			c.JSON(http.StatusBadRequest, errorResponse(c, "overlap must be a duration such as 24h"))
+			return
+		}
+	}
+
+	apiKey, key, err := newAPIKey(request.ExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	err = store.RotateAPIKey(c.Request.Context(), id, apiKey, apiKey.CreatedAt.Add(overlap))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(c, "api key not found"))
+		return
+	} else if errors.Is(err, databases.ErrAPIKeyInactive) {
+		c.JSON(http.StatusConflict, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, models.APIKeyResponse{Key: key, APIKey: *apiKey})
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/auth"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/databases"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newSession starts a refresh token family for the player and returns its tokens.
+func newSession(ctx context.Context, store databases.AuthStore, tokens *auth.Tokens, playerID int) (*models.TokenResponse, error) {
+	familyID, err := auth.NewFamilyID()
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, refreshHash, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	err = store.CreateRefreshToken(ctx, models.RefreshToken{
+		PlayerID:  playerID,
+		FamilyID:  familyID,
+		TokenHash: refreshHash,
+		ExpiresAt: now.Add(tokens.RefreshTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tokenResponse(ctx, store, tokens, playerID, refreshToken, now)
+}
+
+// tokenResponse signs an access token carrying the current roles of the player.
+func tokenResponse(ctx context.Context, store databases.AuthStore, tokens *auth.Tokens, playerID int, refreshToken string, now time.Time) (*models.TokenResponse, error) {
+	roles, err := store.GetRoles(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := tokens.AccessToken(playerID, append([]string{middleware.RolePlayer}, roles...), now)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TokenResponse{
+		PlayerID:     playerID,
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(tokens.AccessTTL.Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// @Summary      Register an account
+// @Description  Create a player at the lowest level with the username as name and a password, and log it in. The password is stored as a bcrypt hash.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        account  body  models.RegisterRequest  true  "Username and password of the new player"
+// @Success      201  {object}  models.TokenResponse  "Player created and logged in"
+// @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid input or a password over 72 bytes"
+// @Failure      409  {object}  models.ErrorResponse  "Username is already taken"
+// @Failure      429  {object}  models.ErrorResponse  "Rate limit exceeded"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Router       /auth/register [post]
+func Register(c *gin.Context, store databases.AuthStore, tokens *auth.Tokens) {
+	var request models.RegisterRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+	// The max of the binding counts characters, bcrypt counts bytes
+	if len(request.Password) > auth.MaxPasswordBytes {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "password is longer than "+strconv.Itoa(auth.MaxPasswordBytes)+" bytes"))
+		return
+	}
+	passwordHash, err := auth.HashPassword(request.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+
+	playerID, err := store.Register(c.Request.Context(), request.Username, passwordHash)
+	if errors.Is(err, databases.ErrDuplicateUsername) {
+		c.JSON(http.StatusConflict, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+
+	session, err := newSession(c.Request.Context(), store, tokens, playerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	playersRegistered.Inc()
+	c.JSON(http.StatusCreated, session)
+}
+
+// @Summary      Log in
+// @Description  Exchange a username and password for a short lived access token and a refresh token.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        credentials  body  models.LoginRequest  true  "Username and password"
+// @Success      200  {object}  models.TokenResponse  "Logged in"
+// @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid input"
+// @Failure      401  {object}  models.ErrorResponse  "Invalid username or password"
+// @Failure      429  {object}  models.ErrorResponse  "Rate limit exceeded"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Router       /auth/login [post]
+func Login(c *gin.Context, store databases.AuthStore, tokens *auth.Tokens) {
+	var request models.LoginRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+
+	var passwordHash string
+	credential, err := store.GetCredential(c.Request.Context(), request.Username)
+	if err == nil {
+		passwordHash = credential.PasswordHash
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	if err := auth.CheckPassword(passwordHash, request.Password); err != nil {
+		logins.WithLabelValues("failure").Inc()
+		c.JSON(http.StatusUnauthorized, errorResponse(c, err.Error()))
+		return
+	}
+
+	session, err := newSession(c.Request.Context(), store, tokens, credential.PlayerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	logins.WithLabelValues("success").Inc()
+	c.JSON(http.StatusOK, session)
+}
+
+// @Summary      Refresh a session
+// @Description  Exchange a refresh token for a new access token and a new refresh token. Each refresh token can be used once, using it again ends the session. The new access token carries the current roles of the player.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        refresh  body  models.RefreshRequest  true  "Refresh token of the session"
+// @Success      200  {object}  models.TokenResponse  "New tokens of the session"
+// @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid input"
+// @Failure      401  {object}  models.ErrorResponse  "Refresh token is invalid, expired or already used"
+// @Failure      429  {object}  models.ErrorResponse  "Rate limit exceeded"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Router       /auth/refresh [post]
+func Refresh(c *gin.Context, store databases.AuthStore, tokens *auth.Tokens) {
+	var request models.RefreshRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+	refreshToken, refreshHash, err := auth.NewRefreshToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+
+	now := time.Now().UTC()
+	next := models.RefreshToken{
+		TokenHash: refreshHash,
+		ExpiresAt: now.Add(tokens.RefreshTTL),
+		CreatedAt: now,
+	}
+	err = store.RotateRefreshToken(c.Request.Context(), auth.HashRefreshToken(request.RefreshToken), &next)
+	if errors.Is(err, databases.ErrInvalidRefreshToken) || errors.Is(err, databases.ErrRefreshTokenReused) {
+		c.JSON(http.StatusUnauthorized, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+
+	session, err := tokenResponse(c.Request.Context(), store, tokens, next.PlayerID, refreshToken, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+// @Summary      Log out
+// @Description  End the session of a refresh token, the token and every token rotated from the same login stop working. Access tokens already issued stay valid until they expire.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        refresh  body  models.RefreshRequest  true  "Refresh token of the session"
+// @Success      200  {object}  models.SuccessResponse  "Logged out"
+// @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid input"
+// @Failure      401  {object}  models.ErrorResponse  "Unknown refresh token"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Router       /auth/logout [post]
+func Logout(c *gin.Context, store databases.AuthStore) {
+	var request models.RefreshRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+	err := store.RevokeRefreshToken(c.Request.Context(), auth.HashRefreshToken(request.RefreshToken))
+	if errors.Is(err, databases.ErrInvalidRefreshToken) {
+		c.JSON(http.StatusUnauthorized, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse{})
+}
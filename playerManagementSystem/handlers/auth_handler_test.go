@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+)
+
+func TestRegisterPasswordLength(t *testing.T) {
+	r := testRouter(t)
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		status   int
+	}{
+		{name: "72 bytes", username: "alice", password: strings.Repeat("a", 72), status: http.StatusCreated},
+		{name: "36 characters of 2 bytes", username: "bob", password: strings.Repeat("é", 36), status: http.StatusCreated},
+		{name: "40 characters of 2 bytes", username: "carol", password: strings.Repeat("é", 40), status: http.StatusBadRequest},
+		{name: "73 bytes", username: "dave", password: strings.Repeat("a", 73), status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		request := models.RegisterRequest{Username: tt.username, Password: tt.password}
+		if w := serve(r, http.MethodPost, "/auth/register", "", request); w.Code != tt.status {
+			t.Errorf("%s: POST /auth/register status = %d, want %d: %s", tt.name, w.Code, tt.status, w.Body)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/databases"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// importBatchSize is the number of rows created per transaction during an import.
+const importBatchSize = 500
+
+// exportFlushEvery is the number of rows written before flushing an export to the client.
+const exportFlushEvery = 100
+
+// maxNDJSONLine is the longest accepted line of an NDJSON import.
+const maxNDJSONLine = 1024 * 1024
+
+// errInvalidImport marks an import body that cannot be read at all.
+var errInvalidImport = errors.New("invalid import")
+
+// playerImporter collects parsed rows and sends them to the store in batches.
+type playerImporter struct {
+	store    databases.PlayerStore
+	batch    []models.PlayerImportRow
+	response models.PlayerImportResponse
+}
+
+func (i *playerImporter) add(ctx context.Context, row models.PlayerImportRow) error {
+	i.batch = append(i.batch, row)
+	if len(i.batch) >= importBatchSize {
+		return i.flush(ctx)
+	}
+	return nil
+}
+
+// reject records a row that could not be parsed.
+func (i *playerImporter) reject(line int, reason string) {
+	i.response.Results = append(i.response.Results, models.PlayerImportResult{
+		Line:   line,
+		Status: models.ImportError,
+		Reason: reason,
+	})
+}
+
+func (i *playerImporter) flush(ctx context.Context) error {
+	if len(i.batch) == 0 {
+		return nil
+	}
+	results, err := i.store.ImportPlayers(ctx, i.batch)
+	if err != nil {
+		return err
+	}
+	i.response.Results = append(i.response.Results, results...)
+	i.batch = i.batch[:0]
+	return nil
+}
+
+// finish imports the last batch and summarises the results in line order.
+func (i *playerImporter) finish(ctx context.Context) (*models.PlayerImportResponse, error) {
+	if err := i.flush(ctx); err != nil {
+		return nil, err
+	}
+	if i.response.Results == nil {
+		i.response.Results = []models.PlayerImportResult{}
+	}
+	sort.SliceStable(i.response.Results, func(a, b int) bool {
+		return i.response.Results[a].Line < i.response.Results[b].Line
+	})
+	for _, result := range i.response.Results {
+		switch result.Status {
+		case models.ImportCreated:
+			i.response.Created++
+		case models.ImportSkipped:
+			i.response.Skipped++
+		default:
+			i.response.Errors++
+		}
+	}
+	return &i.response, nil
+}
+
+// readCSVRows reads a CSV with a header containing at least the name and lv columns.
+func readCSVRows(ctx context.Context, r io.Reader, importer *playerImporter) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("%w: error reading csv header: %v", errInvalidImport, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	nameColumn, hasName := columns["name"]
+	lvColumn, hasLV := columns["lv"]
+	if !hasName || !hasLV {
+		return fmt.Errorf("%w: csv header must contain name and lv", errInvalidImport)
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			// The reader position is unreliable after a syntax error, stop here
+			importer.reject(parseErr.Line, parseErr.Err.Error())
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%w: error reading csv: %v", errInvalidImport, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(record) <= nameColumn || len(record) <= lvColumn {
+			importer.reject(line, "missing name or lv column")
+			continue
+		}
+		lv, err := strconv.Atoi(strings.TrimSpace(record[lvColumn]))
+		if err != nil {
+			importer.reject(line, "invalid lv")
+			continue
+		}
+		err = importer.add(ctx, models.PlayerImportRow{
+			Line: line,
+			Name: strings.TrimSpace(record[nameColumn]),
+			LV:   lv,
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readNDJSONRows reads one JSON object with name and lv per line, blank lines are ignored.
+func readNDJSONRows(ctx context.Context, r io.Reader, importer *playerImporter) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLine)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var row models.PlayerImportRow
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			importer.reject(line, "invalid json: "+err.Error())
+			continue
+		}
+		row.Line = line
+		row.Name = strings.TrimSpace(row.Name)
+		if err := importer.add(ctx, row); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: error reading ndjson: %v", errInvalidImport, err)
+	}
+	return nil
+}
+
+// bulkFormat returns the format of an import or export, from the format query or the content type.
+func bulkFormat(c *gin.Context) string {
+	if format := c.Query("format"); format != "" {
+		return format
+	}
+	switch c.ContentType() {
+	case "text/csv":
+		return "csv"
+	case "application/x-ndjson", "application/jsonl":
+		return "ndjson"
+	}
+	return ""
+}
+
+// @Summary      Import players
+// @Description  Create players in bulk from a CSV with a name,lv header or from NDJSON with one {"name","lv"} object per line. Rows are created in batched transactions. Each row is reported as created, skipped when an active player already has the name, or error with the reason.
+// @Tags         players
+// @Accept       text/csv,application/x-ndjson
+// @Produce      json
+// @Param        format  query  string  false  "Format of the body, defaults to the Content-Type"  Enums(csv, ndjson)
+// @Param        rows    body   string  true   "Players to import"
+// @Success      200  {object}  models.PlayerImportResponse  "Result of every imported row"
+// @Failure      400  {object}  models.ErrorResponse  "Unknown format or unreadable body"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission players:create"
+// @Failure      429  {object}  models.ErrorResponse  "Rate limit exceeded"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /players/import [post]
+func ImportPlayers(c *gin.Context, store databases.PlayerStore) {
+	importer := &playerImporter{store: store}
+
+	var err error
+	switch bulkFormat(c) {
+	case "csv":
+		err = readCSVRows(c.Request.Context(), c.Request.Body, importer)
+	case "ndjson":
+		err = readNDJSONRows(c.Request.Context(), c.Request.Body, importer)
+	default:
+		c.JSON(http.StatusBadRequest, errorResponse(c, "format must be csv or ndjson"))
+		return
+	}
+	if errors.Is(err, errInvalidImport) {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+
+	response, err := importer.finish(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary      Export players
+// @Description  Stream every active player with its level as CSV or NDJSON. Rows are written while they are read from the database.
+// @Tags         players
+// @Produce      text/csv,application/x-ndjson
+// @Param        format  query  string  false  "Export format, csv by default"  Enums(csv, ndjson)
+// @Success      200  {string}  string  "Players, one per line"
+// @Failure      400  {object}  models.ErrorResponse  "Unknown format"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission players:export"
+// @Failure      429  {object}  models.ErrorResponse  "Rate limit exceeded"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /players/export [get]
+func ExportPlayers(c *gin.Context, store databases.PlayerStore) {
+	format := c.DefaultQuery("format", "csv")
+
+	var write func(models.PlayerRank) error
+	var flush func() error
+	switch format {
+	case "csv":
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", `attachment; filename="players.csv"`)
+		writer := csv.NewWriter(c.Writer)
+		if err := writer.Write([]string{"id", "name", "lv", "xp"}); err != nil {
+			return
+		}
+		write = func(p models.PlayerRank) error {
+			return writer.Write([]string{strconv.Itoa(p.ID), p.Name, strconv.Itoa(p.LV), strconv.Itoa(p.XP)})
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case "ndjson":
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", `attachment; filename="players.ndjson"`)
+		encoder := json.NewEncoder(c.Writer)
+		write = func(p models.PlayerRank) error {
+			return encoder.Encode(p)
+		}
+		flush = func() error { return nil }
+	default:
+		c.JSON(http.StatusBadRequest, errorResponse(c, "format must be csv or ndjson"))
+		return
+	}
+
+	c.Status(http.StatusOK)
+	written := 0
+	err := store.ExportPlayers(c.Request.Context(), func(p models.PlayerRank) error {
+		if err := write(p); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushEvery == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		// The status is already sent, only record the error for the logger
+		_ = c.Error(err)
+		return
+	}
+	c.Writer.Flush()
+}
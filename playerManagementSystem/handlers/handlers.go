@@ -2,10 +2,22 @@ package handlers
 
 import (
 	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/auth"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/databases"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
+// errorResponse is the body of a failed request, carrying its ID to find its logs.
+func errorResponse(c *gin.Context, message string) models.ErrorResponse {
+	return models.ErrorResponse{Error: message, RequestID: middleware.RequestID(c)}
+}
+
 type RouterFunctionHeader struct {
 	context *gin.Context
 	db      *sql.DB
@@ -18,17 +30,107 @@ func NewRouterFunctionHeader(c *gin.Context, db *sql.DB) *RouterFunctionHeader {
 	}
 }
 
-func SetupPlayersRoutes(players *gin.RouterGroup, db *sql.DB) {
+// requestActor is the authenticated player or API key making a change, it is
+// recorded in the player audit trail.
+func requestActor(c *gin.Context) string {
+	if playerID, ok := middleware.PlayerID(c); ok {
+		return "player:" + strconv.Itoa(playerID)
+	}
+	if apiKeyID, ok := middleware.APIKeyID(c); ok {
+		return "api_key:" + strconv.FormatInt(apiKeyID, 10)
+	}
+	return "anonymous"
+}
+
+// versionETag is the strong entity tag of a player version.
+func versionETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// parseIfMatch returns the player version required by an If-Match header,
+// 0 when the header is missing or "*". It only accepts a single strong entity tag.
+func parseIfMatch(header string) (int, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, true
+	}
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.Atoi(header[1 : len(header)-1])
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
+
+func SetupPlayersRoutes(players *gin.RouterGroup, store databases.PlayerStore, limiter middleware.RateLimitStore) {
 	// Player routes
-	players.GET("/", func(c *gin.Context) { GetPlayers(c, db) })
-	players.POST("/", func(c *gin.Context) { CreatePlayer(c, db) })
-	players.GET("/:id", func(c *gin.Context) { GetPlayer(c, db) })
-	players.PUT("/:id", func(c *gin.Context) { UpdatePlayer(c, db) })
-	players.DELETE("/:id", func(c *gin.Context) { DeletePlayer(c, db) })
+	players.Use(middleware.RateLimit(limiter, DefaultRateLimit))
+	players.GET("/", func(c *gin.Context) { GetPlayers(c, store) })
+	players.POST("/", Policy.Require(PermPlayersCreate), func(c *gin.Context) { CreatePlayer(c, store) })
+	players.GET("/leaderboard", func(c *gin.Context) { GetLeaderboard(c, store) })
+	players.GET("/search", func(c *gin.Context) { SearchPlayers(c, store) })
+	players.POST("/import", middleware.RateLimit(limiter, BulkRateLimit), Policy.Require(PermPlayersCreate), func(c *gin.Context) { ImportPlayers(c, store) })
+	players.GET("/export", middleware.RateLimit(limiter, BulkRateLimit), Policy.Require(PermPlayersExport), func(c *gin.Context) { ExportPlayers(c, store) })
+	players.GET("/:id", func(c *gin.Context) { GetPlayer(c, store) })
+	players.PUT("/:id", Policy.Require(PermPlayersUpdate), func(c *gin.Context) { UpdatePlayer(c, store) })
+	players.PATCH("/:id", Policy.RequireSelfOr("id", PermPlayersUpdate), func(c *gin.Context) { PatchPlayer(c, store) })
+	players.DELETE("/:id", Policy.Require(PermPlayersDelete), func(c *gin.Context) { DeletePlayer(c, store) })
+	players.GET("/:id/rank", func(c *gin.Context) { GetPlayerRank(c, store) })
+	players.POST("/:id/xp", Policy.Require(PermPlayersAwardXP), func(c *gin.Context) { AwardXP(c, store) })
+	players.POST("/:id/restore", Policy.Require(PermPlayersDelete), func(c *gin.Context) { RestorePlayer(c, store) })
+	players.GET("/:id/audit", Policy.RequireSelfOr("id", PermPlayersAudit), func(c *gin.Context) { GetPlayerAudit(c, store) })
+}
+
+func SetupRolesRoutes(players *gin.RouterGroup, store databases.AuthStore) {
+	// Role routes, nested under the players
+	players.GET("/:id/roles", Policy.RequireSelfOr("id", PermRolesRead), func(c *gin.Context) { GetPlayerRoles(c, store) })
+	players.PUT("/:id/roles", Policy.Require(PermRolesWrite), func(c *gin.Context) { SetPlayerRoles(c, store) })
 }
 
-func SetupLevelsRoutes(levels *gin.RouterGroup, db *sql.DB) {
+func SetupLevelsRoutes(levels *gin.RouterGroup, store databases.LevelStore, limiter middleware.RateLimitStore) {
 	// Level routes
-	levels.GET("/", func(c *gin.Context) { GetLevels(c, db) })
-	levels.POST("/", func(c *gin.Context) { CreateLevel(c, db) })
+	levels.Use(middleware.RateLimit(limiter, DefaultRateLimit))
+	levels.GET("/", func(c *gin.Context) { GetLevels(c, store) })
+	levels.POST("/", Policy.Require(PermLevelsWrite), func(c *gin.Context) { CreateLevel(c, store) })
+	levels.GET("/:id", func(c *gin.Context) { GetLevel(c, store) })
+	levels.PUT("/:id", Policy.Require(PermLevelsWrite), func(c *gin.Context) { UpdateLevel(c, store) })
+	levels.DELETE("/:id", Policy.Require(PermLevelsWrite), func(c *gin.Context) { DeleteLevel(c, store) })
+}
+
+func SetupAPIKeysRoutes(apiKeys *gin.RouterGroup, store databases.APIKeyStore, limiter middleware.RateLimitStore) {
+	// API key routes
+	apiKeys.Use(middleware.RateLimit(limiter, DefaultRateLimit))
+	apiKeys.GET("/", Policy.Require(PermAPIKeysManage), func(c *gin.Context) { ListAPIKeys(c, store) })
+	apiKeys.POST("/", Policy.Require(PermAPIKeysManage), func(c *gin.Context) { CreateAPIKey(c, store) })
+	apiKeys.DELETE("/:id", Policy.Require(PermAPIKeysManage), func(c *gin.Context) { RevokeAPIKey(c, store) })
+	apiKeys.POST("/:id/rotate", Policy.Require(PermAPIKeysManage), func(c *gin.Context) { RotateAPIKey(c, store) })
+}
+
+func SetupAuthRoutes(authentication *gin.RouterGroup, store databases.AuthStore, tokens *auth.Tokens, limiter middleware.RateLimitStore) {
+	// Auth routes
+	authentication.Use(middleware.RateLimit(limiter, DefaultRateLimit))
+	authentication.POST("/register", middleware.RateLimit(limiter, RegisterRateLimit), func(c *gin.Context) { Register(c, store, tokens) })
+	authentication.POST("/login", middleware.RateLimit(limiter, LoginRateLimit), func(c *gin.Context) { Login(c, store, tokens) })
+	authentication.POST("/refresh", middleware.RateLimit(limiter, RefreshRateLimit), func(c *gin.Context) { Refresh(c, store, tokens) })
+	authentication.POST("/logout", func(c *gin.Context) { Logout(c, store) })
+}
+
+// SetupRouter registers the auth, levels, players, roles and API keys routes on r using the given store,
+// limiting the requests of every caller with the token buckets of limiter.
+func SetupRouter(r *gin.Engine, store databases.Store, tokens *auth.Tokens, limiter middleware.RateLimitStore) {
+	// Setup Auth routes
+	SetupAuthRoutes(r.Group("/auth"), store, tokens, limiter)
+
+	// Setup Levels routes
+	SetupLevelsRoutes(r.Group("/levels"), store, limiter)
+
+	// Setup Players routes
+	players := r.Group("/players")
+	SetupPlayersRoutes(players, store, limiter)
+	SetupRolesRoutes(players, store)
+
+	// Setup API keys routes
+	SetupAPIKeysRoutes(r.Group("/api_keys"), store, limiter)
 }
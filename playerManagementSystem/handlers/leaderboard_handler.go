@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/databases"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Player leaderboard
+// @Description  Retrieve a page of players ordered by level, highest first. Players on the same level share the same dense rank. Pass next_cursor as after_id to fetch the next page.
+// @Tags         players
+// @Accept       json
+// @Produce      json
+// @Param        after_id  query  int  false  "Return players after the player with this ID on the leaderboard"
+// @Param        limit     query  int  false  "Maximum number of players in the page (default 50, max 500)"
+// @Success      200  {object}  models.Page[models.LeaderboardEntry]  "A page of the leaderboard"
+// @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid query parameters"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Router       /players/leaderboard [get]
+func GetLeaderboard(c *gin.Context, store databases.PlayerStore) {
+	var leaderboardQuery models.LeaderboardQuery
+	if err := c.ShouldBindQuery(&leaderboardQuery); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+	page, err := store.GetLeaderboard(c.Request.Context(), leaderboardQuery)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary      Retrieve a player's rank
+// @Description  Get the dense rank of a specific player on the leaderboard, players on the same level share the same rank.
+// @Tags         players
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Player ID"
+// @Success      200  {object}  models.LeaderboardEntry  "Player with its rank"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid ID supplied"
+// @Failure      404  {object}  models.ErrorResponse  "Player not found"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Router       /players/{id}/rank [get]
+func GetPlayerRank(c *gin.Context, store databases.PlayerStore) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid player id"))
+		return
+	}
+	entry, err := store.GetPlayerRank(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/databases"
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
@@ -18,10 +20,10 @@ import (
 // @Success      200  {object}  []models.Level 			"A list of levels"
 // @Failure      500  {object}  models.ErrorResponse	"Internal server error"
 // @Router       /levels [get]
-func GetLevels(c *gin.Context, db *sql.DB) {
-	levels, err := databases.GetLevelsData(db)
+func GetLevels(c *gin.Context, store databases.LevelStore) {
+	levels, err := store.GetLevelsData(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
 	c.JSON(http.StatusOK, levels)
@@ -35,18 +37,149 @@ func GetLevels(c *gin.Context, db *sql.DB) {
 // @Param        level  body  models.Level  true  "Level details to be created"
 // @Success      201  {object}  models.CreateResponse "Level created successfully with the generated ID"
 // @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid input"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission levels:write"
+// @Failure      409  {object}  models.ErrorResponse  "Another level already uses this LV"
 // @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /levels [post]
-func CreateLevel(c *gin.Context, db *sql.DB) {
+func CreateLevel(c *gin.Context, store databases.LevelStore) {
 	var newLevel models.Level
 	if err := c.BindJSON(&newLevel); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
 		return
 	}
-	id, err := databases.AddLevel(db, newLevel.Name, newLevel.LV)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+	id, err := store.AddLevel(c.Request.Context(), newLevel)
+	if errors.Is(err, databases.ErrDuplicateLV) {
+		c.JSON(http.StatusConflict, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
 	c.JSON(http.StatusCreated, models.CreateResponse{ID: id})
 }
+
+// @Summary      Retrieve a level by ID
+// @Description  Get details of a specific level identified by its ID from the database.
+// @Tags         levels
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Level ID"
+// @Success      200  {object}  models.Level  "Level details"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid ID supplied"
+// @Failure      404  {object}  models.ErrorResponse  "Level not found"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Router       /levels/{id} [get]
+func GetLevel(c *gin.Context, store databases.LevelStore) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid level id"))
+		return
+	}
+	level, err := store.GetLevel(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, level)
+}
+
+// @Summary      Update a level
+// @Description  Rename or renumber an existing level. The LV must stay unique across levels.
+// @Tags         levels
+// @Accept       json
+// @Produce      json
+// @Param        id     path  int           true  "Level ID"
+// @Param        level  body  models.Level  true  "Level details to be updated"
+// @Success      200  {object}  models.SuccessResponse  "Level updated successfully"
+// @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid input"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission levels:write"
+// @Failure      404  {object}  models.ErrorResponse  "Level not found"
+// @Failure      409  {object}  models.ErrorResponse  "Another level already uses this LV"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /levels/{id} [put]
+func UpdateLevel(c *gin.Context, store databases.LevelStore) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid level id"))
+		return
+	}
+	var level models.Level
+	if err := c.BindJSON(&level); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+	level.ID = id
+
+	err = store.UpdateLevel(c.Request.Context(), level)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if errors.Is(err, databases.ErrDuplicateLV) {
+		c.JSON(http.StatusConflict, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse{})
+}
+
+// @Summary      Delete a level
+// @Description  Remove a level from the database. If players are still on this level the delete is refused, unless reassign_to_lv names the level to move them to. The moved players get a new version and an audit entry.
+// @Tags         levels
+// @Accept       json
+// @Produce      json
+// @Param        id              path   int  true   "Level ID to be deleted"
+// @Param        reassign_to_lv  query  int  false  "LV of the level that players on the deleted level are moved to"
+// @Success      200  {object}  models.SuccessResponse  "Level deleted successfully"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid ID supplied or unknown reassign level"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission levels:write"
+// @Failure      404  {object}  models.ErrorResponse  "Level not found"
+// @Failure      409  {object}  models.ErrorResponse  "Level is still referenced by players"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /levels/{id} [delete]
+func DeleteLevel(c *gin.Context, store databases.LevelStore) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid level id"))
+		return
+	}
+
+	var reassignLV *int
+	if value, ok := c.GetQuery("reassign_to_lv"); ok {
+		lv, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(c, "invalid reassign_to_lv"))
+			return
+		}
+		reassignLV = &lv
+	}
+
+	err = store.DeleteLevel(c.Request.Context(), id, reassignLV, requestActor(c))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if errors.Is(err, databases.ErrLevelInUse) {
+		c.JSON(http.StatusConflict, errorResponse(c, err.Error()))
+		return
+	} else if errors.Is(err, databases.ErrReassignLevelNotFound) {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse{})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
+)
+
+func TestDeleteLevelReassign(t *testing.T) {
+	r := testRouter(t)
+	admin := bearer(t, middleware.RoleAdmin)
+
+	if w := serve(r, http.MethodPost, "/levels/", admin, models.Level{Name: "Elite", LV: 6}); w.Code != http.StatusCreated {
+		t.Fatalf("POST /levels status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+	}
+	w := serve(r, http.MethodPost, "/players/", admin, models.PlayerRank{Name: "alice", LV: 5})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST /players status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+	}
+	path := "/players/" + strconv.Itoa(decode[models.CreateResponse](t, w).ID)
+
+	var levelID int
+	for _, level := range decode[[]models.Level](t, serve(r, http.MethodGet, "/levels/", "", nil)) {
+		if level.LV == 5 {
+			levelID = level.ID
+		}
+	}
+	levelPath := "/levels/" + strconv.Itoa(levelID)
+
+	if w = serve(r, http.MethodDelete, levelPath, admin, nil); w.Code != http.StatusConflict {
+		t.Errorf("DELETE %s of a level in use status = %d, want %d", levelPath, w.Code, http.StatusConflict)
+	}
+	if w = serve(r, http.MethodDelete, levelPath+"?reassign_to_lv=7", admin, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("DELETE %s to an unknown lv status = %d, want %d", levelPath, w.Code, http.StatusBadRequest)
+	}
+	if w = serve(r, http.MethodDelete, levelPath+"?reassign_to_lv=6", admin, nil); w.Code != http.StatusOK {
+		t.Fatalf("DELETE %s status = %d, want %d: %s", levelPath, w.Code, http.StatusOK, w.Body)
+	}
+
+	w = serve(r, http.MethodGet, path, "", nil)
+	if player := decode[models.PlayerRank](t, w); player.LV != 6 {
+		t.Errorf("GET %s lv = %d, want 6", path, player.LV)
+	}
+	if got := w.Header().Get("ETag"); got != `"2"` {
+		t.Errorf("GET %s ETag = %q, want %q", path, got, `"2"`)
+	}
+
+	audits := decode[[]models.PlayerAudit](t, serve(r, http.MethodGet, path+"/audit", admin, nil))
+	if len(audits) != 1 || audits[0].Field != "lv" || audits[0].OldValue != "5" || audits[0].NewValue != "6" {
+		t.Errorf("GET %s/audit = %+v, want the lv moved from 5 to 6", path, audits)
+	}
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Domain metrics of the players and auth routes.
+var (
+	playersRegistered = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "players_registered_total",
+		Help:      "Players registered through /auth/register.",
+	})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "logins_total",
+		Help:      "Logins by result, success or failure.",
+	}, []string{"result"})
+)
+
+func init() {
+	prometheus.MustRegister(playersRegistered, logins)
+}
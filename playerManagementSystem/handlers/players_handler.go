@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/databases"
 	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
@@ -12,20 +15,63 @@ import (
 )
 
 // @Summary      List players
-// @Description  Retrieve a list of players and their ranks from the database.
+// @Description  Retrieve a page of players and their ranks from the database, with optional sorting and filters. Pass next_cursor as after_id to fetch the next page.
 // @Tags         players
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  []models.PlayerRank  "A list of players with their ranks"
+// @Param        after_id     query  int     false  "Return players after the player with this ID in the requested order"
+// @Param        limit        query  int     false  "Maximum number of players in the page (default 50, max 500)"
+// @Param        sort         query  string  false  "Sort key"  Enums(id, name, lv)
+// @Param        order        query  string  false  "Sort order"  Enums(asc, desc)
+// @Param        min_lv       query  int     false  "Only players at or above this level"
+// @Param        max_lv       query  int     false  "Only players at or below this level"
+// @Param        name_prefix  query  string  false  "Only players whose name starts with this prefix"
+// @Param        include_deleted  query  bool  false  "Also list soft deleted players"
+// @Success      200  {object}  models.Page[models.PlayerRank]  "A page of players with their ranks"
+// @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid query parameters"
 // @Failure      500  {object}  models.ErrorResponse  "Internal server error"
 // @Router       /players [get]
-func GetPlayers(c *gin.Context, db *sql.DB) {
-	playerRanks, err := databases.GetPlayersData(db)
+func GetPlayers(c *gin.Context, store databases.PlayerStore) {
+	var playerQuery models.PlayerQuery
+	if err := c.ShouldBindQuery(&playerQuery); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+	page, err := store.GetPlayersData(c.Request.Context(), playerQuery)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// @Summary      Search players by name
+// @Description  Find players whose name matches q case-insensitively, exactly, by prefix, as a substring or with a few typos. Results are ordered by relevance, best first.
+// @Tags         players
+// @Accept       json
+// @Produce      json
+// @Param        q      query  string  true   "Text to search in player names"
+// @Param        limit  query  int     false  "Maximum number of results (default 20, max 100)"
+// @Success      200  {object}  []models.PlayerSearchResult  "Matching players with their relevance"
+// @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid query parameters"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Router       /players/search [get]
+func SearchPlayers(c *gin.Context, store databases.PlayerStore) {
+	var searchQuery models.PlayerSearchQuery
+	if err := c.ShouldBindQuery(&searchQuery); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+	if strings.TrimSpace(searchQuery.Q) == "" {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "q must not be blank"))
+		return
+	}
+	results, err := store.SearchPlayers(c.Request.Context(), searchQuery)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
-	c.JSON(http.StatusOK, playerRanks)
+	c.JSON(http.StatusOK, results)
 }
 
 // @Summary      Create a new player
@@ -36,18 +82,22 @@ func GetPlayers(c *gin.Context, db *sql.DB) {
 // @Param        player  body  models.PlayerRank  true  "Player details to be created"
 // @Success      201  {object}  models.CreateResponse  "Player created successfully with the generated ID"
 // @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid input"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission players:create"
 // @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /players [post]
-func CreatePlayer(c *gin.Context, db *sql.DB) {
+func CreatePlayer(c *gin.Context, store databases.PlayerStore) {
 	var newPlayerRank models.PlayerRank
 	if err := c.BindJSON(&newPlayerRank); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
 		return
 	}
 
-	id, err := databases.AddPlayer(db, newPlayerRank.Name, newPlayerRank.LV)
+	id, err := store.AddPlayer(c.Request.Context(), newPlayerRank.Name, newPlayerRank.LV)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
 	c.JSON(http.StatusCreated, models.CreateResponse{ID: id})
@@ -59,17 +109,26 @@ func CreatePlayer(c *gin.Context, db *sql.DB) {
 // @Accept       json
 // @Produce      json
 // @Param        id  path  int  true  "Player ID"
-// @Success      200  {object}  models.PlayerRank  "Player details"
+// @Success      200  {object}  models.PlayerRank  "Player details, the ETag header holds the player version"
 // @Failure      400  {object}  models.ErrorResponse  "Invalid ID supplied"
+// @Failure      404  {object}  models.ErrorResponse  "Player not found"
 // @Failure      500  {object}  models.ErrorResponse  "Internal server error"
 // @Router       /players/{id} [get]
-func GetPlayer(c *gin.Context, db *sql.DB) {
-	id, _ := strconv.Atoi(c.Param("id"))
-	playerRank, err := databases.GetPlayer(db, id)
+func GetPlayer(c *gin.Context, store databases.PlayerStore) {
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid player id"))
+		return
+	}
+	playerRank, err := store.GetPlayer(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
+	c.Header("ETag", versionETag(playerRank.Version))
 	c.JSON(http.StatusOK, playerRank)
 }
 
@@ -78,42 +137,243 @@ func GetPlayer(c *gin.Context, db *sql.DB) {
 // @Tags         players
 // @Accept       json
 // @Produce      json
+// @Param        id      path  int                true  "Player ID"
 // @Param        player  body  models.PlayerRank  true  "Player details to be updated"
 // @Success      200  {object}  models.SuccessResponse  "Player updated successfully"
-// @Failure      400  {object}  error  "Bad request due to invalid input"
-// @Failure      500  {object}  error  "Internal server error"
-// @Router       /players [put]
-func UpdatePlayer(c *gin.Context, db *sql.DB) {
+// @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid input or unknown lv"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission players:update"
+// @Failure      404  {object}  models.ErrorResponse  "Player not found"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /players/{id} [put]
+func UpdatePlayer(c *gin.Context, store databases.PlayerStore) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid player id"))
+		return
+	}
 	var playerRank models.PlayerRank
 	if err := c.BindJSON(&playerRank); err != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
 		return
 	}
+	playerRank.ID = id
 
-	err := databases.UpdatePlayer(db, playerRank)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+	err = store.UpdatePlayer(c.Request.Context(), playerRank, requestActor(c))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if errors.Is(err, databases.ErrLevelNotFound) {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
 	c.JSON(http.StatusOK, models.SuccessResponse{})
 }
 
+// @Summary      Partially update a player
+// @Description  Apply a JSON Merge Patch (RFC 7396) to a player. Members of the patch replace name, lv, display_name, avatar_url, country or locale, a null removes a profile field and omitted members are left unchanged. Send the ETag of the player in If-Match to only apply the patch if nobody changed the player since it was read. Players can patch their own profile but not their lv.
+// @Tags         players
+// @Accept       application/merge-patch+json
+// @Produce      json
+// @Param        If-Match  header  string  false  "ETag of the player the patch was made against"
+// @Param        id     path  int     true  "Player ID"
+// @Param        patch  body  object  true  "JSON Merge Patch of the player"
+// @Success      200  {object}  models.PlayerRank  "Patched player, the ETag header holds the new version"
+// @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid patch"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission players:update to patch another player or change lv"
+// @Failure      404  {object}  models.ErrorResponse  "Player not found"
+// @Failure      412  {object}  models.ErrorResponse  "Player changed since the If-Match version"
+// @Failure      415  {object}  models.ErrorResponse  "Body is not a merge patch"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /players/{id} [patch]
+func PatchPlayer(c *gin.Context, store databases.PlayerStore) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid player id"))
+		return
+	}
+	if contentType := c.ContentType(); contentType != "application/merge-patch+json" && contentType != "application/json" {
+		c.JSON(http.StatusUnsupportedMediaType, errorResponse(c, "content type must be application/merge-patch+json"))
+		return
+	}
+	version, ok := parseIfMatch(c.GetHeader("If-Match"))
+	if !ok {
+		c.JSON(http.StatusPreconditionFailed, errorResponse(c, "If-Match must be * or the ETag of the player"))
+		return
+	}
+	patch, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+
+	// Players patching their own profile cannot change their level
+	if !Policy.Allows(c, PermPlayersUpdate) {
+		var members map[string]json.RawMessage
+		if json.Unmarshal(patch, &members) == nil {
+			if _, ok := members["lv"]; ok {
+				c.JSON(http.StatusForbidden, errorResponse(c, "missing permission "+PermPlayersUpdate+" to change lv"))
+				return
+			}
+		}
+	}
+
+	playerRank, err := store.PatchPlayer(c.Request.Context(), id, patch, version, requestActor(c))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if errors.Is(err, databases.ErrVersionMismatch) {
+		c.JSON(http.StatusPreconditionFailed, errorResponse(c, err.Error()))
+		return
+	} else if errors.Is(err, databases.ErrInvalidPatch) {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.Header("ETag", versionETag(playerRank.Version))
+	c.JSON(http.StatusOK, playerRank)
+}
+
 // @Summary      Delete a player
-// @Description  Remove a player from the database using the provided player ID.
+// @Description  Soft delete a player using the provided player ID. The player is hidden from the API but kept in the database and can be restored.
 // @Tags         players
 // @Accept       json
 // @Produce      json
 // @Param        id  path  int  true  "Player ID to be deleted"
 // @Success      200  {object}  models.SuccessResponse	"Player deleted successfully"
 // @Failure      400  {object}  models.ErrorResponse  	"Invalid ID supplied"
+// @Failure      401  {object}  models.ErrorResponse  	"Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  	"Missing permission players:delete"
+// @Failure      404  {object}  models.ErrorResponse  	"Player not found"
 // @Failure      500  {object}  models.ErrorResponse  	"Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
 // @Router       /players/{id} [delete]
-func DeletePlayer(c *gin.Context, db *sql.DB) {
-	id, _ := strconv.Atoi(c.Param("id"))
-	err := databases.DeletePlayer(db, id)
+func DeletePlayer(c *gin.Context, store databases.PlayerStore) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid player id"))
+		return
+	}
+	err = store.DeletePlayer(c.Request.Context(), id, requestActor(c))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, models.SuccessResponse{})
+}
+
+// @Summary      Award experience points
+// @Description  Add experience points to a player. The player is promoted in the same transaction to the highest level whose xp_threshold is reached, possibly across several levels. Returns the player before and after the award.
+// @Tags         players
+// @Accept       json
+// @Produce      json
+// @Param        id   path  int               true  "Player ID"
+// @Param        xp   body  models.XPRequest  true  "Experience points to award"
+// @Success      200  {object}  models.XPAward  "Player before and after the award"
+// @Failure      400  {object}  models.ErrorResponse  "Bad request due to invalid input"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission players:award_xp"
+// @Failure      404  {object}  models.ErrorResponse  "Player not found"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /players/{id}/xp [post]
+func AwardXP(c *gin.Context, store databases.PlayerStore) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid player id"))
+		return
+	}
+	var xpRequest models.XPRequest
+	if err := c.BindJSON(&xpRequest); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+	award, err := store.AwardXP(c.Request.Context(), id, xpRequest.Amount, requestActor(c))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, award)
+}
+
+// @Summary      Restore a deleted player
+// @Description  Undo the soft delete of a player using the provided player ID.
+// @Tags         players
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Player ID to be restored"
+// @Success      200  {object}  models.SuccessResponse  "Player restored successfully"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid ID supplied"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission players:delete"
+// @Failure      404  {object}  models.ErrorResponse  "Player not found"
+// @Failure      409  {object}  models.ErrorResponse  "Player is not deleted"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /players/{id}/restore [post]
+func RestorePlayer(c *gin.Context, store databases.PlayerStore) {
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid player id"))
+		return
+	}
+	err = store.RestorePlayer(c.Request.Context(), id, requestActor(c))
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(c, err.Error()))
+		return
+	} else if errors.Is(err, databases.ErrPlayerNotDeleted) {
+		c.JSON(http.StatusConflict, errorResponse(c, err.Error()))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
 		return
 	}
 	c.JSON(http.StatusOK, models.SuccessResponse{})
 }
+
+// @Summary      Player audit trail
+// @Description  List who changed which field of a player and when, oldest change first.
+// @Tags         players
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Player ID"
+// @Success      200  {object}  []models.PlayerAudit  "Changes of the player"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid ID supplied"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission players:audit to read another player"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /players/{id}/audit [get]
+func GetPlayerAudit(c *gin.Context, store databases.PlayerStore) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid player id"))
+		return
+	}
+	audits, err := store.GetPlayerAudit(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, audits)
+}
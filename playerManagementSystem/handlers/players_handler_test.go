@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/auth"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/databases"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+var testSecret = []byte("players-handler-test-secret")
+
+// testRouter serves the routes on a MemoryStore holding level 5 behind the
+// bearer token authentication, as main does.
+func testRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	tokens := auth.NewTokens(testSecret, time.Hour, time.Hour)
+	r := gin.New()
+	r.Use(middleware.Authenticate(middleware.NewKeySet(testSecret)))
+	SetupRouter(r, databases.NewMemoryStore(), tokens, middleware.NewMemoryRateLimitStore())
+	if w := serve(r, http.MethodPost, "/levels/", bearer(t, middleware.RoleAdmin), models.Level{Name: "Veteran", LV: 5}); w.Code != http.StatusCreated {
+		t.Fatalf("POST /levels status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+	}
+	return r
+}
+
+// bearer returns an access token of player 1 holding the roles.
+func bearer(t *testing.T, roles ...string) string {
+	t.Helper()
+	token, err := auth.NewTokens(testSecret, time.Hour, time.Hour).AccessToken(1, roles, time.Now())
+	if err != nil {
+		t.Fatalf("AccessToken returned error: %v", err)
+	}
+	return "Bearer " + token
+}
+
+func serve(r *gin.Engine, method, path, authorization string, body any) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&payload).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil {
+		t.Fatalf("error decoding %s: %v", w.Body.String(), err)
+	}
+	return v
+}
+
+func TestPlayersLifecycle(t *testing.T) {
+	r := testRouter(t)
+	admin := bearer(t, middleware.RoleAdmin)
+
+	w := serve(r, http.MethodPost, "/players/", admin, models.PlayerRank{Name: "alice", LV: 5})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST /players status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+	}
+	id := decode[models.CreateResponse](t, w).ID
+	path := "/players/" + strconv.Itoa(id)
+
+	w = serve(r, http.MethodGet, path, "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s status = %d, want %d: %s", path, w.Code, http.StatusOK, w.Body)
+	}
+	if player := decode[models.PlayerRank](t, w); player.Name != "alice" || player.LV != 5 {
+		t.Errorf("GET %s = %+v, want alice at level 5", path, player)
+	}
+	if got := w.Header().Get("ETag"); got != `"1"` {
+		t.Errorf("GET %s ETag = %q, want %q", path, got, `"1"`)
+	}
+	if w = serve(r, http.MethodPut, path, admin, models.PlayerRank{LV: 99}); w.Code != http.StatusBadRequest {
+		t.Errorf("PUT %s to an unknown lv status = %d, want %d", path, w.Code, http.StatusBadRequest)
+	}
+
+	w = serve(r, http.MethodGet, "/players/", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /players status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	if page := decode[models.Page[models.PlayerRank]](t, w); len(page.Data) != 1 || page.Data[0].ID != id {
+		t.Errorf("GET /players = %+v, want player %d", page.Data, id)
+	}
+
+	w = serve(r, http.MethodDelete, path, admin, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("DELETE %s status = %d, want %d: %s", path, w.Code, http.StatusOK, w.Body)
+	}
+	if w = serve(r, http.MethodGet, path, "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("GET %s of a deleted player status = %d, want %d", path, w.Code, http.StatusNotFound)
+	}
+	if w = serve(r, http.MethodDelete, path, admin, nil); w.Code != http.StatusNotFound {
+		t.Errorf("DELETE %s of a deleted player status = %d, want %d", path, w.Code, http.StatusNotFound)
+	}
+
+	w = serve(r, http.MethodPost, path+"/restore", admin, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST %s/restore status = %d, want %d: %s", path, w.Code, http.StatusOK, w.Body)
+	}
+	if w = serve(r, http.MethodPost, path+"/restore", admin, nil); w.Code != http.StatusConflict {
+		t.Errorf("POST %s/restore of an active player status = %d, want %d", path, w.Code, http.StatusConflict)
+	}
+	if w = serve(r, http.MethodGet, path, "", nil); w.Code != http.StatusOK {
+		t.Errorf("GET %s of a restored player status = %d, want %d", path, w.Code, http.StatusOK)
+	}
+}
+
+func TestPlayersErrors(t *testing.T) {
+	r := testRouter(t)
+	admin := bearer(t, middleware.RoleAdmin)
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		body          any
+		status        int
+	}{
+		{name: "list unknown sort", method: http.MethodGet, path: "/players/?sort=xp", status: http.StatusBadRequest},
+		{name: "get invalid id", method: http.MethodGet, path: "/players/abc", status: http.StatusBadRequest},
+		{name: "get missing player", method: http.MethodGet, path: "/players/99", status: http.StatusNotFound},
+		{name: "create without token", method: http.MethodPost, path: "/players/", body: models.PlayerRank{Name: "bob", LV: 5}, status: http.StatusUnauthorized},
+		{name: "create with invalid token", method: http.MethodPost, path: "/players/", authorization: "Bearer invalid", body: models.PlayerRank{Name: "bob", LV: 5}, status: http.StatusUnauthorized},
+		{name: "create as player", method: http.MethodPost, path: "/players/", authorization: bearer(t, middleware.RolePlayer), body: models.PlayerRank{Name: "bob", LV: 5}, status: http.StatusForbidden},
+		{name: "delete as game master", method: http.MethodDelete, path: "/players/99", authorization: bearer(t, middleware.RoleGameMaster), status: http.StatusForbidden},
+		{name: "update missing player", method: http.MethodPut, path: "/players/99", authorization: admin, body: models.PlayerRank{LV: 5}, status: http.StatusNotFound},
+		{name: "delete invalid id", method: http.MethodDelete, path: "/players/abc", authorization: admin, status: http.StatusBadRequest},
+		{name: "delete missing player", method: http.MethodDelete, path: "/players/99", authorization: admin, status: http.StatusNotFound},
+		{name: "restore missing player", method: http.MethodPost, path: "/players/99/restore", authorization: admin, status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := serve(r, tt.method, tt.path, tt.authorization, tt.body); w.Code != tt.status {
+			t.Errorf("%s: %s %s status = %d, want %d: %s", tt.name, tt.method, tt.path, w.Code, tt.status, w.Body)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/middleware"
+)
+
+// Permissions of the players, levels, roles and API keys routes.
+const (
+	PermLevelsWrite    = "levels:write"
+	PermPlayersCreate  = "players:create"
+	PermPlayersUpdate  = "players:update"
+	PermPlayersDelete  = "players:delete"
+	PermPlayersAwardXP = "players:award_xp"
+	PermPlayersAudit   = "players:audit"
+	PermPlayersExport  = "players:export"
+	PermRolesRead      = "roles:read"
+	PermRolesWrite     = "roles:write"
+	PermAPIKeysManage  = "api_keys:manage"
+)
+
+// Policy grants the permissions to roles, players can always update their
+// own profile and read their own audit trail and roles.
+var Policy = middleware.Policy{
+	PermLevelsWrite:    {middleware.RoleGameMaster, middleware.RoleAdmin},
+	PermPlayersCreate:  {middleware.RoleGameMaster, middleware.RoleAdmin},
+	PermPlayersUpdate:  {middleware.RoleSupport, middleware.RoleGameMaster, middleware.RoleAdmin},
+	PermPlayersDelete:  {middleware.RoleSupport, middleware.RoleAdmin},
+	PermPlayersAwardXP: {middleware.RoleGameMaster, middleware.RoleAdmin},
+	PermPlayersAudit:   {middleware.RoleSupport, middleware.RoleAdmin},
+	PermPlayersExport:  {middleware.RoleSupport, middleware.RoleAdmin},
+	PermRolesRead:      {middleware.RoleSupport, middleware.RoleAdmin},
+	PermRolesWrite:     {middleware.RoleAdmin},
+	PermAPIKeysManage:  {middleware.RoleAdmin},
+}
+
+// Rate limits of the routes, every caller gets DefaultRateLimit on each group
+// and the auth and bulk routes a stricter one on top.
+var (
+	DefaultRateLimit  = middleware.RateLimitPolicy{Name: "players", Limit: 120, Window: time.Minute}
+	RegisterRateLimit = middleware.RateLimitPolicy{Name: "auth:register", Limit: 5, Window: time.Minute}
+	LoginRateLimit    = middleware.RateLimitPolicy{Name: "auth:login", Limit: 10, Window: time.Minute}
+	RefreshRateLimit  = middleware.RateLimitPolicy{Name: "auth:refresh", Limit: 30, Window: time.Minute}
+	BulkRateLimit     = middleware.RateLimitPolicy{Name: "players:bulk", Limit: 5, Window: time.Minute}
+)
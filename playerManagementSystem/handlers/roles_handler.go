@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/databases"
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/playerManagementSystem/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary      Roles of a player
+// @Description  List the roles granted to a player besides player, which every account has. Players can read their own roles.
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Param        id  path  int  true  "Player ID"
+// @Success      200  {object}  models.PlayerRoles  "Roles of the player"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid ID supplied"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission roles:read"
+// @Failure      404  {object}  models.ErrorResponse  "Player not found"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /players/{id}/roles [get]
+func GetPlayerRoles(c *gin.Context, store databases.AuthStore) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid player id"))
+		return
+	}
+	roles, err := store.GetRoles(c.Request.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(c, "player not found"))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, models.PlayerRoles{PlayerID: id, Roles: roles})
+}
+
+// @Summary      Grant roles to a player
+// @Description  Replace the roles granted to a player with support, game-master, finance or admin. The roles are carried by the access tokens issued from the next login or refresh.
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Param        id     path  int                  true  "Player ID"
+// @Param        roles  body  models.RolesRequest  true  "Roles of the player"
+// @Success      200  {object}  models.PlayerRoles  "Roles of the player"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid ID or unknown role supplied"
+// @Failure      401  {object}  models.ErrorResponse  "Authentication required"
+// @Failure      403  {object}  models.ErrorResponse  "Missing permission roles:write"
+// @Failure      404  {object}  models.ErrorResponse  "Player not found"
+// @Failure      500  {object}  models.ErrorResponse  "Internal server error"
+// @Security     BearerAuth
+// @Security     ApiKeyAuth
+// @Router       /players/{id}/roles [put]
+func SetPlayerRoles(c *gin.Context, store databases.AuthStore) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, "invalid player id"))
+		return
+	}
+	var request models.RolesRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(c, err.Error()))
+		return
+	}
+
+	err = store.SetRoles(c.Request.Context(), id, request.Roles)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, errorResponse(c, "player not found"))
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+
+	roles, err := store.GetRoles(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(c, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, models.PlayerRoles{PlayerID: id, Roles: roles})
+}
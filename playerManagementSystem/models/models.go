@@ -1,29 +1,248 @@
 package models
 
+import "time"
+
 // table and return struct for Level
 type Level struct {
-	ID   int    `json:"id"`
-	Name string `json:"name" binding:"required"`
-	LV   int    `json:"lv" binding:"required"`
+	ID          int    `json:"id"`
+	Name        string `json:"name" binding:"required"`
+	LV          int    `json:"lv" binding:"required"`
+	XPThreshold int    `json:"xp_threshold" binding:"min=0"`
 }
 
 // table for Player
 type Player struct {
-	ID      int    `json:"id"`
-	Name    string `json:"name" binding:"required"`
-	LevelID int    `json:"level_id" binding:"required"`
+	ID          int        `json:"id"`
+	Name        string     `json:"name" binding:"required"`
+	LevelID     int        `json:"level_id" binding:"required"`
+	XP          int        `json:"xp"`
+	DisplayName *string    `json:"display_name"`
+	AvatarURL   *string    `json:"avatar_url"`
+	Country     *string    `json:"country"`
+	Locale      *string    `json:"locale"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Version     int        `json:"version"`
+	DeletedAt   *time.Time `json:"deleted_at"`
 }
 
-// return struct for player
+// return struct for player, the profile fields are only filled by the list and single player endpoints
 type PlayerRank struct {
-	ID   int    `json:"id"`
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	LV          int        `json:"lv"`
+	XP          int        `json:"xp"`
+	DisplayName *string    `json:"display_name,omitempty"`
+	AvatarURL   *string    `json:"avatar_url,omitempty"`
+	Country     *string    `json:"country,omitempty"`
+	Locale      *string    `json:"locale,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	Version     int        `json:"version,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+// XPRequest represents the experience points awarded to a player.
+type XPRequest struct {
+	Amount int `json:"amount" binding:"required,min=1"`
+}
+
+// XPAward represents a player before and after experience points were awarded.
+type XPAward struct {
+	Before PlayerRank `json:"before"`
+	After  PlayerRank `json:"after"`
+}
+
+// PlayerQuery represents the cursor, sorting and filters for listing players.
+type PlayerQuery struct {
+	AfterID        int    `form:"after_id" binding:"omitempty,min=1"`
+	Limit          int    `form:"limit" binding:"omitempty,min=1,max=500"`
+	Sort           string `form:"sort" binding:"omitempty,oneof=id name lv"`
+	Order          string `form:"order" binding:"omitempty,oneof=asc desc"`
+	MinLV          *int   `form:"min_lv"`
+	MaxLV          *int   `form:"max_lv"`
+	NamePrefix     string `form:"name_prefix"`
+	IncludeDeleted bool   `form:"include_deleted"`
+}
+
+// PlayerSearchQuery represents the text and size of a player name search.
+type PlayerSearchQuery struct {
+	Q     string `form:"q" binding:"required,max=100"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// How a searched player name matched the query
+const (
+	MatchExact     = "exact"
+	MatchPrefix    = "prefix"
+	MatchSubstring = "substring"
+	MatchFuzzy     = "fuzzy"
+)
+
+// return struct for player search, ordered by score from 1 (exact) down to 0
+type PlayerSearchResult struct {
+	Match string  `json:"match"`
+	Score float64 `json:"score"`
+	PlayerRank
+}
+
+// Status of an imported player row
+const (
+	ImportCreated = "created"
+	ImportSkipped = "skipped"
+	ImportError   = "error"
+)
+
+// PlayerImportRow represents one row of a bulk player import.
+type PlayerImportRow struct {
+	Line int    `json:"-"`
 	Name string `json:"name"`
 	LV   int    `json:"lv"`
 }
 
-// ErrorResponse represents an error response with a single error message.
+// PlayerImportResult represents the outcome of one imported row.
+type PlayerImportResult struct {
+	Line   int    `json:"line"`
+	Status string `json:"status"`
+	ID     int    `json:"id,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// PlayerImportResponse represents the outcome of a bulk player import.
+type PlayerImportResponse struct {
+	Created int                  `json:"created"`
+	Skipped int                  `json:"skipped"`
+	Errors  int                  `json:"errors"`
+	Results []PlayerImportResult `json:"results"`
+}
+
+// table for PlayerAudit, one row per changed player field
+type PlayerAudit struct {
+	ID        int64     `json:"id"`
+	PlayerID  int       `json:"player_id"`
+	Actor     string    `json:"actor"`
+	Field     string    `json:"field"`
+	OldValue  string    `json:"old_value"`
+	NewValue  string    `json:"new_value"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// table for PlayerCredential, the login of a player
+type Credential struct {
+	PlayerID     int    `json:"player_id"`
+	Username     string `json:"username"`
+	PasswordHash string `json:"-"`
+}
+
+// table for RefreshToken, only the hash of the token is stored.
+// Tokens rotated from the same login share the FamilyID.
+type RefreshToken struct {
+	ID        int64      `json:"id"`
+	PlayerID  int        `json:"player_id"`
+	FamilyID  string     `json:"family_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// RegisterRequest represents a new account, the player is created with the username as name.
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=64"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+// LoginRequest represents the credentials of a login.
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshRequest represents the refresh token of a session.
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenResponse represents the tokens of a session, expires_in is in seconds.
+type TokenResponse struct {
+	PlayerID     int    `json:"player_id"`
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// PlayerRoles represents the roles granted to a player besides player,
+// which every account has.
+type PlayerRoles struct {
+	PlayerID int      `json:"player_id"`
+	Roles    []string `json:"roles"`
+}
+
+// RolesRequest represents the roles granted to a player, replacing the current ones.
+type RolesRequest struct {
+	Roles []string `json:"roles" binding:"dive,oneof=support game-master finance admin"`
+}
+
+// table for APIKey, the machine identity of another service. Only the hash
+// of the key is stored, the prefix tells keys apart when listing them.
+type APIKey struct {
+	ID            int64      `json:"id"`
+	Name          string     `json:"name"`
+	Prefix        string     `json:"prefix"`
+	KeyHash       string     `json:"-"`
+	Scopes        []string   `json:"scopes"`
+	RotatedFromID *int64     `json:"rotated_from_id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	LastUsedAt    *time.Time `json:"last_used_at"`
+}
+
+// CreateAPIKeyRequest represents a new API key, the scopes are the permissions
+// of the services it may use, such as rooms:update.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=64"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,required,max=64"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// RotateAPIKeyRequest represents how long the rotated key keeps working next to
+// the new one, as a duration such as 24h, and when the new key expires.
+type RotateAPIKeyRequest struct {
+	Overlap   string     `json:"overlap"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// APIKeyResponse represents a created or rotated API key, the key is only returned once.
+type APIKeyResponse struct {
+	Key string `json:"key"`
+	APIKey
+}
+
+// LeaderboardQuery represents the cursor for paging through the leaderboard.
+type LeaderboardQuery struct {
+	AfterID int `form:"after_id" binding:"omitempty,min=1"`
+	Limit   int `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+// return struct for leaderboard, players with the same LV share the same dense rank
+type LeaderboardEntry struct {
+	Rank int `json:"rank"`
+	PlayerRank
+}
+
+// Page represents one page of a list endpoint, next_cursor is null on the last page.
+type Page[T any] struct {
+	Data       []T  `json:"data"`
+	NextCursor *int `json:"next_cursor"`
+}
+
+// ErrorResponse represents an error response with a single error message and
+// the ID of the request, to find its logs.
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // CreateResponse represents an id after created a item.
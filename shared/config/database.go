@@ -0,0 +1,108 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/XSAM/otelsql"
+	"github.com/go-sql-driver/mysql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DSN is a MySQL data source name such as user:password@tcp(127.0.0.1:3306)/SpinnrTechnology,
+// its password is redacted when the config is printed.
+type DSN string
+
+// Parse parses the DSN and turns on parseTime, which the services need to scan DATETIME columns.
+func (d DSN) Parse() (*mysql.Config, error) {
+	cfg, err := mysql.ParseDSN(string(d))
+	if err != nil {
+		return nil, err
+	}
+	cfg.ParseTime = true
+	return cfg, nil
+}
+
+func (d DSN) Redacted() string {
+	if d == "" {
+		return ""
+	}
+	cfg, err := mysql.ParseDSN(string(d))
+	if err != nil {
+		return "REDACTED"
+	}
+	if cfg.Passwd != "" {
+		cfg.Passwd = "REDACTED"
+	}
+	return cfg.FormatDSN()
+}
+
+// Database is the MySQL connection and the settings of its pool.
+type Database struct {
+	DSN             DSN           `config:"dsn" env:"DB_CONNECTION_STRING"`
+	MaxOpenConns    int           `config:"max_open_conns" env:"DB_MAX_OPEN_CONNS" default:"25"`
+	MaxIdleConns    int           `config:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" default:"25"`
+	ConnMaxLifetime time.Duration `config:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" default:"5m"`
+	ConnMaxIdleTime time.Duration `config:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" default:"5m"`
+}
+
+func (d Database) Validate() error {
+	if d.DSN == "" {
+		return fmt.Errorf("DB_CONNECTION_STRING is required")
+	}
+	if _, err := d.DSN.Parse(); err != nil {
+		return fmt.Errorf("invalid DB_CONNECTION_STRING: %w", err)
+	}
+	if d.MaxOpenConns < 0 || d.MaxIdleConns < 0 {
+		return fmt.Errorf("DB_MAX_OPEN_CONNS and DB_MAX_IDLE_CONNS must not be negative")
+	}
+	if d.MaxOpenConns > 0 && d.MaxIdleConns > d.MaxOpenConns {
+		return fmt.Errorf("DB_MAX_IDLE_CONNS must not be more than DB_MAX_OPEN_CONNS")
+	}
+	if d.ConnMaxLifetime < 0 || d.ConnMaxIdleTime < 0 {
+		return fmt.Errorf("DB_CONN_MAX_LIFETIME and DB_CONN_MAX_IDLE_TIME must not be negative")
+	}
+	return nil
+}
+
+// Open connects to MySQL with the pool settings and pings it. Every statement
+// run with the context of a traced request gets a span with its SQL.
+func (d Database) Open() (*sql.DB, error) {
+	cfg, err := d.DSN.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid DB_CONNECTION_STRING: %w", err)
+	}
+	db, err := otelsql.Open("mysql", cfg.FormatDSN(),
+		otelsql.WithAttributes(semconv.DBSystemMySQL, semconv.DBName(cfg.DBName)),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			SpanFilter:           inTrace,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(d.MaxOpenConns)
+	db.SetMaxIdleConns(d.MaxIdleConns)
+	db.SetConnMaxLifetime(d.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(d.ConnMaxIdleTime)
+
+	// Test the connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// inTrace keeps the statements outside of a trace, such as the pings of the
+// pool, from starting traces of their own.
+func inTrace(ctx context.Context, method otelsql.Method, query string, args []driver.NamedValue) bool {
+	return trace.SpanContextFromContext(ctx).IsValid()
+}
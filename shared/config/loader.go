@@ -0,0 +1,265 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// The fields of a config struct are described by tags:
+//
+//	config:"port"        key of the field in the YAML or TOML file, nested structs are sections
+//	env:"PORT"           environment variable of the field
+//	default:":8081"      value when nothing else sets the field
+//	required:"true"      the field must not be empty after loading
+//
+// Fields are strings, ints, bools, time.Duration or comma separated []string.
+// Secret and DSN fields are redacted when the config is printed.
+
+// Validator is implemented by configs checking more than the required fields.
+type Validator interface {
+	Validate() error
+}
+
+// Secret is a string which is never printed.
+type Secret string
+
+func (s Secret) Redacted() string {
+	if s == "" {
+		return ""
+	}
+	return "REDACTED"
+}
+
+// placeholders are the sample secrets of the .env files, a service must not
+// sign or verify tokens with them.
+var placeholders = map[Secret]bool{
+	"change-me-in-production": true,
+	"your_jwt_secret":         true,
+}
+
+// Placeholder reports whether s is a sample secret which was never replaced.
+func (s Secret) Placeholder() bool {
+	return placeholders[s]
+}
+
+// Load fills cfg, a pointer to a config struct, in order of precedence from the
+// environment, the .env file of the working directory, the YAML or TOML file at
+// path and the defaults. Empty variables count as unset, the .env file and path
+// are optional, then cfg is validated.
+func Load(cfg interface{}, path string) error {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error loading .env file: %w", err)
+	}
+
+	file := map[string]interface{}{}
+	if path != "" {
+		var err error
+		if file, err = readFile(path); err != nil {
+			return err
+		}
+	}
+
+	v := reflect.ValueOf(cfg).Elem()
+	if err := load(v, file, ""); err != nil {
+		return err
+	}
+	if err := checkRequired(v, ""); err != nil {
+		return err
+	}
+	if validator, ok := cfg.(Validator); ok {
+		return validator.Validate()
+	}
+	return nil
+}
+
+// readFile decodes the YAML or TOML file at path, chosen by its extension.
+func readFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	file := map[string]interface{}{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &file)
+	case ".toml":
+		err = toml.Unmarshal(data, &file)
+	default:
+		return nil, fmt.Errorf("unknown config file format %q, use .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// load sets the fields of v from the environment, the file section and the defaults.
+func load(v reflect.Value, file map[string]interface{}, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("config")
+		if key == "" {
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			section, _ := file[key].(map[string]interface{})
+			if err := load(v.Field(i), section, prefix+key+"."); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var value string
+		var ok bool
+		if env := field.Tag.Get("env"); env != "" {
+			value = os.Getenv(env)
+			ok = value != ""
+		}
+		if !ok {
+			value, ok = fileValue(file[key])
+		}
+		if !ok {
+			value, ok = field.Tag.Lookup("default")
+		}
+		if !ok {
+			continue
+		}
+		if err := set(v.Field(i), value); err != nil {
+			return fmt.Errorf("invalid %s: %w", name(field, prefix), err)
+		}
+	}
+	return nil
+}
+
+// fileValue formats a value of the file like the environment would hold it.
+func fileValue(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case nil:
+		return "", false
+	case []interface{}:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ","), true
+	default:
+		return fmt.Sprint(value), true
+	}
+}
+
+func set(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+func checkRequired(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("config") == "" {
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			if err := checkRequired(v.Field(i), prefix+field.Tag.Get("config")+"."); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.Tag.Get("required") == "true" && v.Field(i).IsZero() {
+			return fmt.Errorf("%s is required", name(field, prefix))
+		}
+	}
+	return nil
+}
+
+// name is how errors refer to a field, by its environment variable or its key in the file.
+func name(field reflect.StructField, prefix string) string {
+	if env := field.Tag.Get("env"); env != "" {
+		return env
+	}
+	return prefix + field.Tag.Get("config")
+}
+
+// Print writes the effective config as YAML with the secrets redacted.
+func Print(w io.Writer, cfg interface{}) error {
+	out, err := yaml.Marshal(redact(reflect.ValueOf(cfg).Elem()))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+func redact(v reflect.Value) map[string]interface{} {
+	out := map[string]interface{}{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("config")
+		if key == "" {
+			continue
+		}
+		field := v.Field(i)
+		switch value := field.Interface().(type) {
+		case interface{ Redacted() string }:
+			out[key] = value.Redacted()
+		case time.Duration:
+			out[key] = value.String()
+		default:
+			if field.Kind() == reflect.Struct {
+				out[key] = redact(field)
+			} else {
+				out[key] = value
+			}
+		}
+	}
+	return out
+}
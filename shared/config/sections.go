@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RateLimit chooses where the token buckets of the rate limits are kept and
+// which proxies are trusted for the client IP they are keyed by.
+type RateLimit struct {
+	Backend        string   `config:"backend" env:"RATE_LIMIT_BACKEND" default:"memory"`
+	TrustedProxies []string `config:"trusted_proxies" env:"TRUSTED_PROXIES"`
+}
+
+func (r RateLimit) Validate() error {
+	if r.Backend != "memory" && r.Backend != "mysql" {
+		return fmt.Errorf("unknown RATE_LIMIT_BACKEND %q, use memory or mysql", r.Backend)
+	}
+	return nil
+}
+
+// Shutdown is how the service stops on SIGTERM, the readiness is turned off
+// for Delay so the proxy stops sending requests, then the requests in flight
+// and the background workers get DrainTimeout to finish.
+type Shutdown struct {
+	Delay        time.Duration `config:"delay" env:"SHUTDOWN_DELAY" default:"5s"`
+	DrainTimeout time.Duration `config:"drain_timeout" env:"SHUTDOWN_DRAIN_TIMEOUT" default:"20s"`
+}
+
+func (s Shutdown) Validate() error {
+	if s.Delay < 0 || s.DrainTimeout <= 0 {
+		return fmt.Errorf("SHUTDOWN_DELAY must not be negative and SHUTDOWN_DRAIN_TIMEOUT must be positive")
+	}
+	return nil
+}
+
+// Health bounds the readiness checks and lists the services this one depends
+// on by a URL answering 2xx when they are up, such as http://server1:8081/healthz.
+type Health struct {
+	Timeout      time.Duration `config:"timeout" env:"HEALTH_CHECK_TIMEOUT" default:"2s"`
+	Dependencies []string      `config:"dependencies" env:"HEALTH_DEPENDENCIES"`
+}
+
+func (h Health) Validate() error {
+	if h.Timeout <= 0 {
+		return fmt.Errorf("HEALTH_CHECK_TIMEOUT must be positive")
+	}
+	return nil
+}
+
+// Tracing selects where the OpenTelemetry spans go: otlp sends them to a
+// collector at Endpoint, such as http://jaeger:4318, stdout and file write
+// them as JSON lines for local use and none turns tracing off.
+type Tracing struct {
+	Exporter string `config:"exporter" env:"TRACING_EXPORTER" default:"none"`
+	Endpoint string `config:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	File     string `config:"file" env:"TRACING_FILE" default:"traces.json"`
+}
+
+func (t Tracing) Validate() error {
+	switch t.Exporter {
+	case "none", "otlp", "stdout", "file":
+	default:
+		return fmt.Errorf("unknown TRACING_EXPORTER %q, use none, otlp, stdout or file", t.Exporter)
+	}
+	if t.Exporter == "file" && t.File == "" {
+		return fmt.Errorf("TRACING_FILE is required with TRACING_EXPORTER=file")
+	}
+	return nil
+}
+
+// Log sets the level of the records logged, debug, info, warn or error, and
+// their format, json for the log collectors or text to read them locally.
+type Log struct {
+	Level  string `config:"level" env:"LOG_LEVEL" default:"info"`
+	Format string `config:"format" env:"LOG_FORMAT" default:"json"`
+}
+
+func (l Log) Validate() error {
+	switch strings.ToLower(l.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("unknown LOG_LEVEL %q, use debug, info, warn or error", l.Level)
+	}
+	if l.Format != "json" && l.Format != "text" {
+		return fmt.Errorf("unknown LOG_FORMAT %q, use json or text", l.Format)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Build information, set with -ldflags "-X <module>/health.Commit=... -X <module>/health.BuildTime=...".
+// Without them the VCS information embedded by go build is used.
+var (
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Statuses of a report and of its checks.
+const (
+	StatusOK           = "ok"
+	StatusFailing      = "failing"
+	StatusShuttingDown = "shutting_down"
+)
+
+// CheckResult is the outcome of a readiness check.
+type CheckResult struct {
+	Name      string  `json:"name" example:"database"`
+	Status    string  `json:"status" example:"ok"`
+	LatencyMs float64 `json:"latency_ms" example:"1.25"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the body of /healthz and /readyz.
+type Report struct {
+	Status string        `json:"status" example:"ok"`
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+// Version is the body of /version.
+type Version struct {
+	Commit    string `json:"commit" example:"9fd67d9"`
+	BuildTime string `json:"build_time" example:"2024-07-27T16:09:21Z"`
+	GoVersion string `json:"go_version" example:"go1.20.14"`
+}
+
+type check struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// Health serves the health, readiness and build information of a service.
+type Health struct {
+	ready   func() bool
+	timeout time.Duration
+	checks  []check
+}
+
+// New returns the endpoints of a service, ready reports whether it is not
+// shutting down and timeout bounds every readiness check.
+func New(ready func() bool, timeout time.Duration) *Health {
+	return &Health{
+		ready:   ready,
+		timeout: timeout,
+	}
+}
+
+// Add registers a readiness check, the service is ready when all of them pass.
+func (h *Health) Add(name string, run func(ctx context.Context) error) {
+	h.checks = append(h.checks, check{name: name, run: run})
+}
+
+// Register adds /healthz, /readyz and /version to r.
+func (h *Health) Register(r *gin.Engine) {
+	r.GET("/healthz", h.Healthz)
+	r.GET("/readyz", h.Readyz)
+	r.GET("/version", h.Version)
+}
+
+// @Summary      Liveness
+// @Description  Reports that the process is alive, it does not check any dependency.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  health.Report  "The process is alive"
+// @Router       /healthz [get]
+func (h *Health) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, Report{Status: StatusOK})
+}
+
+// @Summary      Readiness
+// @Description  Runs the readiness checks of the service, such as the database, the schema version and the services it depends on, with the status and latency of each.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  health.Report  "Every check passed"
+// @Failure      503  {object}  health.Report  "A check failed or the service is shutting down"
+// @Router       /readyz [get]
+func (h *Health) Readyz(c *gin.Context) {
+	if !h.ready() {
+		c.JSON(http.StatusServiceUnavailable, Report{Status: StatusShuttingDown})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.timeout)
+	defer cancel()
+
+	// Run the checks concurrently so a slow dependency costs at most the timeout
+	report := Report{Status: StatusOK, Checks: make([]CheckResult, len(h.checks))}
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func(i int, name string, run func(ctx context.Context) error) {
+			defer wg.Done()
+			start := time.Now()
+			err := run(ctx)
+			result := CheckResult{
+				Name:      name,
+				Status:    StatusOK,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				result.Status = StatusFailing
+				result.Error = err.Error()
+			}
+			report.Checks[i] = result
+		}(i, check.name, check.run)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusFailing
+			c.JSON(http.StatusServiceUnavailable, report)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary      Build information
+// @Description  Git commit and build time of the binary and the Go version it was built with.
+// @Tags         health
+// @Produce      json
+// @Success      200  {object}  health.Version  "Build information"
+// @Router       /version [get]
+func (h *Health) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, BuildVersion())
+}
+
+// BuildVersion returns the build information of the binary.
+func BuildVersion() Version {
+	version := Version{Commit: Commit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && version.Commit == "":
+				version.Commit = setting.Value
+			case setting.Key == "vcs.time" && version.BuildTime == "":
+				version.BuildTime = setting.Value
+			}
+		}
+	}
+	if version.Commit == "" {
+		version.Commit = "unknown"
+	}
+	if version.BuildTime == "" {
+		version.BuildTime = "unknown"
+	}
+	return version
+}
+
+// Database checks that the database answers a ping.
+func Database(db *sql.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// SchemaVersion checks that the SchemaVersion table holds at least the version
+// the queries of the service are written for.
+func SchemaVersion(db *sql.DB, expected int) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var version sql.NullInt64
+		err := db.QueryRowContext(ctx, `
+			SELECT 
+			MAX(Version) 
+			FROM SchemaVersion
+		`).Scan(&version)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("error querying database with SchemaVersion: %w", err)
+		}
+		if int(version.Int64) < expected {
+			return fmt.Errorf("schema version %d is older than %d", version.Int64, expected)
+		}
+		return nil
+	}
+}
+
+// Dependency checks that a service answers a GET of url, such as its /healthz, with a 2xx status.
+func Dependency(client *http.Client, url string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		response, err := client.Do(request)
+		if err != nil {
+			return err
+		}
+		response.Body.Close()
+		if response.StatusCode < 200 || response.StatusCode > 299 {
+			return fmt.Errorf("%s answered %s", url, response.Status)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,119 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// Workers is the registry of the background workers of a service. Each worker
+// gets a context which is cancelled when the service shuts down and is awaited
+// before the process exits.
+type Workers struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewWorkers() *Workers {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Workers{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Go runs fn in a goroutine, fn must return soon after ctx is done.
+func (w *Workers) Go(fn func(ctx context.Context)) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		fn(w.ctx)
+	}()
+}
+
+// Stop cancels the context of the workers and waits for them to return or for ctx to be done.
+func (w *Workers) Stop(ctx context.Context) error {
+	w.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("error waiting for the workers to stop: %w", ctx.Err())
+	}
+}
+
+// Lifecycle runs the HTTP server of a service until SIGINT or SIGTERM and then
+// shuts it down gracefully, first reporting not ready so the proxy stops
+// sending requests, then draining the requests in flight and stopping the workers.
+type Lifecycle struct {
+	Workers *Workers
+	ready   atomic.Bool
+}
+
+func New() *Lifecycle {
+	return &Lifecycle{
+		Workers: NewWorkers(),
+	}
+}
+
+// Ready reports whether the service accepts requests, it turns false as soon as the shutdown starts.
+func (l *Lifecycle) Ready() bool {
+	return l.ready.Load()
+}
+
+// Run serves until a signal arrives or the server fails. On a signal it waits
+// delay with the readiness turned off, then gives the requests in flight and
+// the workers drainTimeout to finish.
+func (l *Lifecycle) Run(server *http.Server, delay, drainTimeout time.Duration) error {
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	failed := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			failed <- err
+		}
+	}()
+	l.ready.Store(true)
+
+	select {
+	case err := <-failed:
+		l.ready.Store(false)
+		l.Workers.cancel()
+		return err
+	case sig := <-stop:
+		slog.Info("shutting down", "signal", sig.String())
+	}
+
+	l.ready.Store(false)
+	time.Sleep(delay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		l.Workers.cancel()
+		return fmt.Errorf("error draining the requests: %w", err)
+	}
+	if err := l.Workers.Stop(ctx); err != nil {
+		return err
+	}
+	slog.Info("shut down gracefully")
+	return nil
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+// APIKeyHeader carries the API key of service-to-service calls.
+const APIKeyHeader = "X-API-Key"
+
+// Keys of the calling API key in gin.Context.
+const (
+	APIKeyIDKey = "apiKeyID"
+	ScopesKey   = "scopes"
+)
+
+// APIKeyVerifier finds an active API key by the hash of the key and returns
+// its ID and scopes, unknown, revoked and expired keys return sql.ErrNoRows.
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, keyHash string) (int64, []string, error)
+}
+
+// HashAPIKey is the SHA-256 of an API key, only hashes are stored at rest.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// SQLAPIKeys verifies API keys against the APIKey table managed by playerManagementSystem.
+type SQLAPIKeys struct {
+	db *sql.DB
+}
+
+func NewSQLAPIKeys(db *sql.DB) *SQLAPIKeys {
+	return &SQLAPIKeys{
+		db: db,
+	}
+}
+
+func (s *SQLAPIKeys) VerifyAPIKey(ctx context.Context, keyHash string) (int64, []string, error) {
+	now := time.Now().UTC()
+	var id int64
+	var scopes string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT 
+		ID, Scopes 
+		FROM APIKey 
+		WHERE KeyHash = ? AND RevokedAt IS NULL AND (ExpiresAt IS NULL OR ExpiresAt > ?)
+	`, keyHash, now).Scan(
+		&id,
+		&scopes,
+	)
+	if err == sql.ErrNoRows {
+		return 0, nil, fmt.Errorf("error querying database with VerifyAPIKey: %w", err)
+	} else if err != nil {
+		return 0, nil, fmt.Errorf("error scanning row with VerifyAPIKey: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		UPDATE APIKey 
+		SET LastUsedAt = ? 
+		WHERE ID = ?
+	`, now, id)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error querying database with VerifyAPIKey: %w", err)
+	}
+	return id, strings.Fields(scopes), nil
+}
+
+// AuthenticateAPIKey verifies the X-API-Key header of a request and stores the
+// key ID and scopes of the caller in the context. Every request made with a key
+// is logged with the key ID for auditing.
+func AuthenticateAPIKey(keys APIKeyVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		id, scopes, err := keys.VerifyAPIKey(c.Request.Context(), HashAPIKey(key))
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, errorBody(c, "invalid api key"))
+			return
+		} else if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, errorBody(c, err.Error()))
+			return
+		}
+
+		c.Set(APIKeyIDKey, id)
+		c.Set(ScopesKey, scopes)
+		c.Next()
+
+		slog.InfoContext(c.Request.Context(), "api key request", "api_key_id", id, "method", c.Request.Method, "path", c.Request.URL.Path, "status", c.Writer.Status())
+	}
+}
+
+// APIKeyID returns the ID of the calling API key.
+func APIKeyID(c *gin.Context) (int64, bool) {
+	apiKeyID, ok := c.Get(APIKeyIDKey)
+	if !ok {
+		return 0, false
+	}
+	id, ok := apiKeyID.(int64)
+	return id, ok
+}
+
+// Scopes returns the scopes of the calling API key.
+func Scopes(c *gin.Context) []string {
+	scopes, _ := c.Get(ScopesKey)
+	list, _ := scopes.([]string)
+	return list
+}
+
+// HasScope reports whether the calling API key has the scope.
+func HasScope(c *gin.Context, scope string) bool {
+	for _, have := range Scopes(c) {
+		if have == scope {
+			return true
+		}
+	}
+	return false
+}
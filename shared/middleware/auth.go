@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Keys of the authenticated caller in gin.Context.
+const (
+	PlayerIDKey = "playerID"
+	RolesKey    = "roles"
+)
+
+// Claims are the claims of an access token, sub is the player ID.
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+}
+
+// KeySet holds the keys access tokens are verified with, the HS256 secret
+// shared with playerManagementSystem and RS256 public keys by key ID.
+type KeySet struct {
+	secret  []byte
+	rsaKeys map[string]*rsa.PublicKey
+}
+
+func NewKeySet(secret []byte) *KeySet {
+	return &KeySet{
+		secret:  secret,
+		rsaKeys: make(map[string]*rsa.PublicKey),
+	}
+}
+
+// LoadKeySet builds the key set from the HS256 secret and the JWKS file at
+// jwksFile, at least one of them must be set.
+func LoadKeySet(secret []byte, jwksFile string) (*KeySet, error) {
+	keys := NewKeySet(secret)
+	if jwksFile != "" {
+		if err := keys.AddJWKSFile(jwksFile); err != nil {
+			return nil, err
+		}
+	}
+	if len(keys.secret) == 0 && len(keys.rsaKeys) == 0 {
+		return nil, errors.New("JWT_SECRET or JWT_JWKS_FILE is required")
+	}
+	return keys, nil
+}
+
+// jwk is a JSON Web Key, only RSA keys are used.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// AddJWKSFile adds the RSA public keys of a JWKS file.
+func (k *KeySet) AddJWKSFile(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading jwks file: %w", err)
+	}
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &jwks); err != nil {
+		return fmt.Errorf("error parsing jwks file: %w", err)
+	}
+	for _, key := range jwks.Keys {
+		if key.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return fmt.Errorf("error decoding n of key %q: %w", key.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return fmt.Errorf("error decoding e of key %q: %w", key.Kid, err)
+		}
+		k.rsaKeys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return nil
+}
+
+// key returns the key to verify a token with, RS256 tokens pick their key by kid.
+func (k *KeySet) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		if len(k.secret) == 0 {
+			return nil, errors.New("HS256 tokens are not accepted")
+		}
+		return k.secret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := k.rsaKeys[kid]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
+
+// Verify checks the signature and expiry of an access token and returns its claims.
+func (k *KeySet) Verify(token string) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, k.key,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+// Authenticate verifies the bearer token of a request and stores the player ID
+// and roles of the caller in the context. Requests without a token continue
+// anonymously, routes that need a caller use RequireAuth or RequireRole.
+func Authenticate(keys *KeySet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			c.Next()
+			return
+		}
+
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found {
+			unauthorized(c, "authorization header must be a bearer token")
+			return
+		}
+		claims, err := keys.Verify(strings.TrimSpace(token))
+		if err != nil {
+			unauthorized(c, "invalid access token: "+err.Error())
+			return
+		}
+		playerID, err := strconv.Atoi(claims.Subject)
+		if err != nil {
+			unauthorized(c, "invalid access token: sub is not a player id")
+			return
+		}
+
+		c.Set(PlayerIDKey, playerID)
+		c.Set(RolesKey, claims.Roles)
+		c.Next()
+	}
+}
+
+// RequireAuth rejects requests without a valid access token or API key.
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authenticated(c) {
+			unauthorized(c, "authentication required")
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRole rejects requests whose caller has none of the roles.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := PlayerID(c); !ok {
+			unauthorized(c, "authentication required")
+			return
+		}
+		if !HasRole(c, roles...) {
+			c.AbortWithStatusJSON(http.StatusForbidden, errorBody(c, "requires role "+strings.Join(roles, " or ")))
+			return
+		}
+		c.Next()
+	}
+}
+
+// authenticated reports whether the caller is a player or an API key.
+func authenticated(c *gin.Context) bool {
+	_, player := PlayerID(c)
+	_, apiKey := APIKeyID(c)
+	return player || apiKey
+}
+
+// PlayerID returns the ID of the authenticated player.
+func PlayerID(c *gin.Context) (int, bool) {
+	playerID, ok := c.Get(PlayerIDKey)
+	if !ok {
+		return 0, false
+	}
+	id, ok := playerID.(int)
+	return id, ok
+}
+
+// Roles returns the roles of the authenticated player.
+func Roles(c *gin.Context) []string {
+	roles, _ := c.Get(RolesKey)
+	list, _ := roles.([]string)
+	return list
+}
+
+// HasRole reports whether the authenticated player has any of the roles.
+func HasRole(c *gin.Context, roles ...string) bool {
+	for _, have := range Roles(c) {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, errorBody(c, message))
+}
@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+// RateLimitPolicy allows Limit requests per Window for each caller of the
+// routes using it, as a token bucket holding up to Limit tokens and refilling
+// them evenly over the Window.
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// refill returns the tokens of a bucket after elapsed time, capped at the limit.
+func (p RateLimitPolicy) refill(tokens float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return tokens
+	}
+	tokens += float64(p.Limit) * elapsed.Seconds() / p.Window.Seconds()
+	return math.Min(tokens, float64(p.Limit))
+}
+
+// take spends a token of the bucket and returns the tokens left and the result.
+func (p RateLimitPolicy) take(tokens float64) (float64, RateLimitResult) {
+	result := RateLimitResult{Allowed: tokens >= 1}
+	if result.Allowed {
+		tokens--
+	} else {
+		result.RetryAfter = p.timeFor(1 - tokens)
+	}
+	result.Remaining = int(tokens)
+	result.Reset = p.timeFor(float64(p.Limit) - tokens)
+	return tokens, result
+}
+
+// timeFor is how long the bucket takes to refill the tokens.
+func (p RateLimitPolicy) timeFor(tokens float64) time.Duration {
+	return time.Duration(tokens * float64(p.Window) / float64(p.Limit))
+}
+
+// RateLimitResult is the outcome of taking a token, Reset is when the bucket is
+// full again and RetryAfter when the next request is allowed after a rejection.
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// RateLimitStore keeps the token buckets, MemoryRateLimitStore for a single
+// instance and SQLRateLimitStore to share them between instances.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitResult, error)
+}
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	window    time.Duration
+}
+
+// MemoryRateLimitStore keeps the token buckets in memory.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]tokenBucket
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]tokenBucket),
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = tokenBucket{tokens: float64(policy.Limit), updatedAt: now, window: policy.Window}
+	}
+	tokens, result := policy.take(policy.refill(bucket.tokens, now.Sub(bucket.updatedAt)))
+	s.buckets[key] = tokenBucket{tokens: tokens, updatedAt: now, window: policy.Window}
+	return result, nil
+}
+
+// sweep drops the buckets that are full again once a minute, the caller must hold the lock.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.updatedAt) > bucket.window {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// SQLRateLimitStore keeps the token buckets in the RateLimitBucket table so
+// every instance of every service shares them.
+type SQLRateLimitStore struct {
+	db *sql.DB
+}
+
+func NewSQLRateLimitStore(db *sql.DB) *SQLRateLimitStore {
+	return &SQLRateLimitStore{
+		db: db,
+	}
+}
+
+func (s *SQLRateLimitStore) Take(ctx context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitResult, error) {
+	// UpdatedAt keeps microseconds
+	now = now.Truncate(time.Microsecond)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("error starting transaction with Take: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Start a full bucket for a new key, then lock it
+	_, err = tx.ExecContext(ctx, `
+		INSERT IGNORE INTO RateLimitBucket (BucketKey, Tokens, UpdatedAt) 
+		VALUES (?, ?, ?)
+	`, key, policy.Limit, now)
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("error querying database with Take: %w", err)
+	}
+	var tokens float64
+	var updatedAt time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT 
+		Tokens, UpdatedAt 
+		FROM RateLimitBucket 
+		WHERE BucketKey = ? 
+		FOR UPDATE
+	`, key).Scan(
+		&tokens,
+		&updatedAt,
+	)
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("error scanning row with Take: %w", err)
+	}
+
+	tokens, result := policy.take(policy.refill(tokens, now.Sub(updatedAt)))
+	_, err = tx.ExecContext(ctx, `
+		UPDATE RateLimitBucket 
+		SET Tokens = ?, UpdatedAt = ? 
+		WHERE BucketKey = ?
+	`, tokens, now, key)
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("error querying database with Take: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return RateLimitResult{}, fmt.Errorf("error committing transaction with Take: %w", err)
+	}
+	return result, nil
+}
+
+// NewRateLimitStore returns the store of the backend, memory or mysql to share
+// the limits through db.
+func NewRateLimitStore(backend string, db *sql.DB) (RateLimitStore, error) {
+	switch backend {
+	case "", "memory":
+		return NewMemoryRateLimitStore(), nil
+	case "mysql":
+		if db == nil {
+			return nil, fmt.Errorf("rate limit backend mysql needs a database connection")
+		}
+		return NewSQLRateLimitStore(db), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend %q", backend)
+	}
+}
+
+// rateLimitKey is who the limit applies to: the authenticated player, the
+// calling API key or the client IP.
+func rateLimitKey(c *gin.Context) string {
+	if playerID, ok := PlayerID(c); ok {
+		return "player:" + strconv.Itoa(playerID)
+	}
+	if apiKeyID, ok := APIKeyID(c); ok {
+		return "api_key:" + strconv.FormatInt(apiKeyID, 10)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// seconds rounds a duration up to whole seconds for the headers.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// RateLimit limits the requests of each caller to the policy and sets the
+// RateLimit-* headers, rejected requests get 429 with Retry-After.
+func RateLimit(store RateLimitStore, policy RateLimitPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Limit(c, store, policy, rateLimitKey(c)) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// Limit takes a token of the policy for key, such as "player:1", and sets the
+// RateLimit-* headers. When the bucket is empty it aborts with 429 and
+// Retry-After and returns false. Requests are let through when the store fails
+// so an outage of it does not take the routes down.
+func Limit(c *gin.Context, store RateLimitStore, policy RateLimitPolicy, key string) bool {
+	result, err := store.Take(c.Request.Context(), policy.Name+":"+key, policy, time.Now().UTC())
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "rate limit store failed, letting the request through", "policy", policy.Name, "error", err)
+		return true
+	}
+
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%s", policy.Limit, seconds(policy.Window)))
+	c.Header("RateLimit-Limit", strconv.Itoa(policy.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", seconds(result.Reset))
+	if !result.Allowed {
+		c.Header("Retry-After", seconds(result.RetryAfter))
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, errorBody(c, "rate limit of "+policy.Name+" exceeded"))
+		return false
+	}
+	return true
+}
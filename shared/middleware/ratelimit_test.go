@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMemoryRateLimitStoreTake(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	policy := RateLimitPolicy{Name: "test", Limit: 2, Window: time.Minute}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name       string
+		key        string
+		at         time.Duration
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+	}{
+		{name: "first request", key: "player:1", at: 0, allowed: true, remaining: 1},
+		{name: "second request", key: "player:1", at: 0, allowed: true, remaining: 0},
+		{name: "empty bucket", key: "player:1", at: 0, allowed: false, remaining: 0, retryAfter: 30 * time.Second},
+		{name: "other key", key: "player:2", at: 0, allowed: true, remaining: 1},
+		{name: "refilled token", key: "player:1", at: 30 * time.Second, allowed: true, remaining: 0},
+		{name: "full after the window", key: "player:1", at: 2 * time.Minute, allowed: true, remaining: 1},
+	}
+	for _, step := range steps {
+		result, err := store.Take(context.Background(), step.key, policy, now.Add(step.at))
+		if err != nil {
+			t.Fatalf("%s: Take returned error: %v", step.name, err)
+		}
+		if result.Allowed != step.allowed {
+			t.Errorf("%s: Allowed = %v, want %v", step.name, result.Allowed, step.allowed)
+		}
+		if result.Remaining != step.remaining {
+			t.Errorf("%s: Remaining = %d, want %d", step.name, result.Remaining, step.remaining)
+		}
+		if result.RetryAfter != step.retryAfter {
+			t.Errorf("%s: RetryAfter = %v, want %v", step.name, result.RetryAfter, step.retryAfter)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", RateLimit(NewMemoryRateLimitStore(), RateLimitPolicy{Name: "test", Limit: 1, Window: time.Minute}), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	request := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request()
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("first request status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if got := w.Header().Get("RateLimit-Policy"); got != "1;w=60" {
+		t.Errorf("RateLimit-Policy = %q, want %q", got, "1;w=60")
+	}
+	if got := w.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Errorf("RateLimit-Remaining = %q, want %q", got, "0")
+	}
+
+	w = request()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if got := w.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want %q", got, "60")
+	}
+}
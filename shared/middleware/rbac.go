@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Roles carried in the roles claim of an access token. Every account is a
+// player, the other roles are granted by an admin in playerManagementSystem.
+const (
+	RolePlayer     = "player"
+	RoleSupport    = "support"
+	RoleGameMaster = "game-master"
+	RoleFinance    = "finance"
+	RoleAdmin      = "admin"
+)
+
+// Policy maps the permissions of a service to the roles granted them,
+// each service declares its own table next to its routes. API keys are
+// granted the permissions named by their scopes.
+type Policy map[string][]string
+
+// Allows reports whether the authenticated player has a role granted the
+// permission, or the calling API key has it as a scope.
+func (p Policy) Allows(c *gin.Context, permission string) bool {
+	return HasRole(c, p[permission]...) || HasScope(c, permission)
+}
+
+// Require rejects requests whose caller has no role granted the permission.
+func (p Policy) Require(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authenticated(c) {
+			unauthorized(c, "authentication required")
+			return
+		}
+		if !p.Allows(c, permission) {
+			forbidden(c, permission)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSelfOr lets the player named by the path parameter through, every
+// other caller needs a role granted the permission.
+func (p Policy) RequireSelfOr(param string, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authenticated(c) {
+			unauthorized(c, "authentication required")
+			return
+		}
+		if id, err := strconv.Atoi(c.Param(param)); err == nil && IsPlayer(c, id) {
+			c.Next()
+			return
+		}
+		if !p.Allows(c, permission) {
+			forbidden(c, permission)
+			return
+		}
+		c.Next()
+	}
+}
+
+// IsPlayer reports whether the caller is the authenticated player with the ID,
+// API keys are never a player.
+func IsPlayer(c *gin.Context, id int) bool {
+	playerID, ok := PlayerID(c)
+	return ok && playerID == id
+}
+
+func forbidden(c *gin.Context, permission string) {
+	c.AbortWithStatusJSON(http.StatusForbidden, errorBody(c, "missing permission "+permission))
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/RYANCOAL9999/SpinnrTechnologyInterview/shared/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the ID of a request from nginx or the calling service
+// and back in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the key of the request ID in gin.Context.
+const RequestIDKey = "requestID"
+
+// maxRequestIDLength bounds the IDs accepted from the clients.
+const maxRequestIDLength = 128
+
+// AssignRequestID takes the X-Request-ID of the request, or generates one when
+// it is missing or invalid, echoes it in the response and stores it in the
+// context so the logs of the request and of its background work carry it.
+func AssignRequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// RequestID returns the ID of the request.
+func RequestID(c *gin.Context) string {
+	return c.GetString(RequestIDKey)
+}
+
+// validRequestID accepts printable ASCII without spaces, so an ID cannot break the logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns 16 random bytes in hex.
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// errorBody is the body of the requests rejected by the middleware, shaped
+// like the models.ErrorResponse of the handlers.
+func errorBody(c *gin.Context, message string) gin.H {
+	return gin.H{"error": message, "request_id": RequestID(c)}
+}
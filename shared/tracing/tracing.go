@@ -0,0 +1,208 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Instrumentation is the name of the tracer every span of the services is started with.
+const Instrumentation = "github.com/RYANCOAL9999/SpinnrTechnologyInterview"
+
+// Exporters of the spans.
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// Setup installs the tracer provider of service and the W3C trace context
+// propagator. The spans are sent over OTLP/HTTP to endpoint, or the default of
+// the OTEL_EXPORTER_OTLP_* variables when it is empty, written as JSON lines to
+// the standard output or to file, or dropped with the none exporter. The
+// sampler is read from OTEL_TRACES_SAMPLER. The returned function flushes the
+// spans left when the service stops.
+func Setup(service, exporter, endpoint, file string) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	closeFile := func() error { return nil }
+	switch exporter {
+	case ExporterNone:
+		return func(ctx context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(endpoint))
+		}
+		spanExporter, err = otlptracehttp.New(context.Background(), options...)
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New()
+	case ExporterFile:
+		var f *os.File
+		if f, err = os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644); err != nil {
+			return nil, fmt.Errorf("error opening the traces file: %w", err)
+		}
+		closeFile = f.Close
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q, use none, otlp, stdout or file", exporter)
+	}
+	if err != nil {
+		closeFile()
+		return nil, fmt.Errorf("error creating the %s exporter: %w", exporter, err)
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES take precedence over the service name
+	res, err := resource.New(context.Background(),
+		resource.WithAttributes(semconv.ServiceName(service)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		closeFile()
+		return nil, fmt.Errorf("error creating the tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeErr := closeFile(); err == nil {
+			err = closeErr
+		}
+		return err
+	}, nil
+}
+
+// Start starts a span named name as the child of the span of ctx. Without one
+// it starts nothing, so work outside of a request such as the queries of a
+// metrics scrape does not start traces of its own.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return otel.Tracer(Instrumentation).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// Follow returns a context with the cancellation of ctx and the values of
+// from, so the work done after a request with ctx, such as by a background
+// worker, is traced and logged with the span and request ID of the request.
+func Follow(ctx, from context.Context) context.Context {
+	return followed{Context: ctx, from: from}
+}
+
+// followed looks the values up in from before the context it wraps.
+type followed struct {
+	context.Context
+	from context.Context
+}
+
+func (f followed) Value(key any) any {
+	if v := f.from.Value(key); v != nil {
+		return v
+	}
+	return f.Context.Value(key)
+}
+
+// Middleware starts a server span for every request, continuing the trace of
+// the traceparent header sent by nginx or the calling service. The probes and
+// scrapes of /healthz, /readyz and /metrics are not traced.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.URL.Path {
+		case "/healthz", "/readyz", "/metrics":
+			c.Next()
+			return
+		}
+
+		// Spans are named after the route template such as GET /players/:id
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := otel.Tracer(Instrumentation).Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+				semconv.UserAgentOriginal(c.Request.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// Transport traces the requests sent with base and propagates their trace
+// context to the server in the traceparent header.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return &transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := otel.Tracer(Instrumentation).Start(req.Context(), req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLFull(req.URL.Redacted()),
+			semconv.ServerAddress(req.URL.Hostname()),
+		),
+	)
+	defer span.End()
+
+	// The request must not be modified, send a copy carrying the trace context
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}